@@ -0,0 +1,157 @@
+package apps
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/app_helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/env_schema"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/skip_messages"
+)
+
+var instanceEnvVars = []string{
+	"CF_INSTANCE_ADDR",
+	"CF_INSTANCE_GUID",
+	"CF_INSTANCE_INDEX",
+	"CF_INSTANCE_INTERNAL_IP",
+	"CF_INSTANCE_IP",
+	"CF_INSTANCE_PORT",
+	"CF_INSTANCE_PORTS",
+	"PORT",
+}
+
+func appEnvVar(appName, name string) string {
+	var value string
+	Eventually(func() string {
+		value = helpers.CurlApp(Config, appName, "/env/"+name)
+		return value
+	}, Config.DefaultTimeoutDuration()).ShouldNot(BeEmpty())
+	return value
+}
+
+var _ = AppsDescribe("Application Environment", func() {
+	var (
+		appName  string
+		expected env_schema.ExpectedApplication
+	)
+
+	BeforeEach(func() {
+		appName = random_name.CATSRandomName("APP")
+
+		Expect(cf.Cf("push", appName, "--no-start", "-b", Config.GetRubyBuildpackName(), "-m", DEFAULT_MEMORY_LIMIT, "-p", assets.NewAssets().Dora, "-d", Config.GetAppsDomain()).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		app_helpers.SetBackend(appName)
+
+		expected = env_schema.ExpectedApplication{
+			Name:      appName,
+			SpaceName: TestSetup.RegularUserContext().Space,
+			URIs:      []string{strings.ToLower(appName) + "." + Config.GetAppsDomain()},
+			MemoryMB:  256,
+		}
+	})
+
+	AfterEach(func() {
+		app_helpers.AppReport(appName, Config.DefaultTimeoutDuration())
+
+		Expect(cf.Cf("delete", appName, "-f", "-r").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+	})
+
+	Context("for a running instance", func() {
+		var serviceName string
+
+		BeforeEach(func() {
+			serviceName = random_name.CATSRandomName("SVIN")
+			Expect(cf.Cf("create-user-provided-service", serviceName, "-p", `{"username":"admin","password":"my-service"}`).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			Expect(cf.Cf("bind-service", appName, serviceName).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+
+			Expect(cf.Cf("start", appName).Wait(Config.CfPushTimeoutDuration())).To(Exit(0))
+		})
+
+		AfterEach(func() {
+			Expect(cf.Cf("unbind-service", appName, serviceName).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			Expect(cf.Cf("delete-service", serviceName, "-f").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		})
+
+		It("provides a VCAP_APPLICATION matching the app", func() {
+			expected.Instance = true
+			vcapApplication := appEnvVar(appName, "VCAP_APPLICATION")
+
+			Expect(env_schema.ValidateJSON("VCAP_APPLICATION", env_schema.VcapApplicationSchema(expected), vcapApplication)).To(Succeed())
+		})
+
+		It("provides a VCAP_SERVICES containing the bound service", func() {
+			vcapServices := appEnvVar(appName, "VCAP_SERVICES")
+
+			Expect(env_schema.ValidateJSON("VCAP_SERVICES", env_schema.VcapServicesSchema(), vcapServices)).To(Succeed())
+
+			var services map[string][]map[string]interface{}
+			Expect(json.Unmarshal([]byte(vcapServices), &services)).To(Succeed())
+			Expect(services).To(HaveKey("user-provided"))
+			Expect(services["user-provided"]).To(HaveLen(1))
+			Expect(services["user-provided"][0]).To(HaveKeyWithValue("name", serviceName))
+			Expect(services["user-provided"][0]).To(HaveKeyWithValue("credentials", map[string]interface{}{
+				"username": "admin",
+				"password": "my-service",
+			}))
+		})
+
+		It("provides CF_INSTANCE_* and PORT variables", func() {
+			if Config.GetBackend() != "diego" {
+				Skip(skip_messages.SkipDiegoMessage)
+			}
+
+			env := map[string]interface{}{}
+			for _, name := range instanceEnvVars {
+				env[name] = appEnvVar(appName, name)
+			}
+
+			Expect(env_schema.Validate("env", env_schema.InstanceEnvSchema(), env)).To(Succeed())
+			Expect(env_schema.ValidateJSON("CF_INSTANCE_PORTS", env_schema.InstancePortsSchema(), env["CF_INSTANCE_PORTS"].(string))).To(Succeed())
+		})
+	})
+
+	Context("for a task", func() {
+		BeforeEach(func() {
+			if !Config.GetIncludeTasks() {
+				Skip(skip_messages.SkipTasksMessage)
+			}
+
+			Expect(cf.Cf("start", appName).Wait(Config.CfPushTimeoutDuration())).To(Exit(0))
+		})
+
+		It("provides a VCAP_APPLICATION matching the app", func() {
+			// Tasks are sized independently of the app's processes.
+			expected.MemoryMB = 0
+
+			taskName := random_name.CATSRandomName("TASK")
+			Expect(cf.Cf("run-task", appName, `echo "VCAP_APPLICATION=$VCAP_APPLICATION"`, "--name", taskName).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+
+			vcapApplicationLine := regexp.MustCompile(`VCAP_APPLICATION=(\{.*\})`)
+			var vcapApplication string
+			Eventually(func() string {
+				logs := cf.Cf("logs", appName, "--recent").Wait(Config.DefaultTimeoutDuration())
+				Expect(logs).To(Exit(0))
+
+				matches := vcapApplicationLine.FindStringSubmatch(string(logs.Out.Contents()))
+				if matches == nil {
+					return ""
+				}
+				vcapApplication = matches[1]
+				return vcapApplication
+			}, Config.DefaultTimeoutDuration()).ShouldNot(BeEmpty(), fmt.Sprintf("task %s never logged its VCAP_APPLICATION", taskName))
+
+			Expect(env_schema.ValidateJSON("VCAP_APPLICATION", env_schema.VcapApplicationSchema(expected), vcapApplication)).To(Succeed())
+		})
+	})
+})
@@ -0,0 +1,120 @@
+package apps
+
+import (
+	"regexp"
+	"strconv"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/app_helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/skip_messages"
+)
+
+const (
+	diskQuotaMB = 256
+
+	// diskQuotaMarginMB covers the droplet and file system overhead that count
+	// against the disk quota of the instance.
+	diskQuotaMarginMB = 24
+)
+
+var diskExhaustion = regexp.MustCompile(`Wrote (\d+) MB before failing: (.*)\nDisk full: (true|false)`)
+
+// The last "<used> of <quota>" pair on an instance row of `cf app` is disk.
+var instanceDiskUsage = regexp.MustCompile(`(?m)^#0\s+running\s.*\s([0-9.]+)([KMGT]?) of [0-9.]+[KMGT]?\s*$`)
+
+func diskUsageMB(appName string) float64 {
+	app := cf.Cf("app", appName).Wait(Config.DefaultTimeoutDuration())
+	Expect(app).To(Exit(0))
+
+	matches := instanceDiskUsage.FindStringSubmatch(string(app.Out.Contents()))
+	if matches == nil {
+		return 0
+	}
+
+	used, err := strconv.ParseFloat(matches[1], 64)
+	Expect(err).NotTo(HaveOccurred())
+
+	switch matches[2] {
+	case "K":
+		return used / 1024
+	case "G":
+		return used * 1024
+	case "T":
+		return used * 1024 * 1024
+	case "":
+		return used / (1024 * 1024)
+	}
+	return used
+}
+
+var _ = AppsDescribe("Disk quota", func() {
+	var appName string
+
+	BeforeEach(func() {
+		if Config.GetBackend() != "diego" {
+			Skip(skip_messages.SkipDiegoMessage)
+		}
+
+		appName = random_name.CATSRandomName("APP")
+
+		Expect(cf.Cf("push", appName,
+			"--no-start",
+			"-b", Config.GetGoBuildpackName(),
+			"-m", DEFAULT_MEMORY_LIMIT,
+			"-k", strconv.Itoa(diskQuotaMB)+"M",
+			"-p", assets.NewAssets().Golang,
+			"-d", Config.GetAppsDomain(),
+		).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		app_helpers.SetBackend(appName)
+		Expect(cf.Cf("start", appName).Wait(Config.CfPushTimeoutDuration())).To(Exit(0))
+	})
+
+	AfterEach(func() {
+		app_helpers.AppReport(appName, Config.DefaultTimeoutDuration())
+
+		Expect(cf.Cf("delete", appName, "-f", "-r").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+	})
+
+	It("fails writes past the quota and reports disk usage close to it", func() {
+		By("filling most of the disk quota")
+		Expect(helpers.CurlAppWithTimeout(Config, appName, "/disk/write/200", Config.LongCurlTimeoutDuration())).
+			To(ContainSubstring("Wrote 200 MB"))
+
+		By("observing the disk usage reported by `cf app`")
+		Eventually(func() float64 {
+			return diskUsageMB(appName)
+		}, Config.DefaultTimeoutDuration(), "5s").Should(BeNumerically(">=", 200))
+		Expect(diskUsageMB(appName)).To(BeNumerically("<=", diskQuotaMB))
+
+		By("writing past the disk quota")
+		exhaust := helpers.CurlAppWithTimeout(Config, appName, "/disk/exhaust", Config.LongCurlTimeoutDuration())
+		matches := diskExhaustion.FindStringSubmatch(exhaust)
+		Expect(matches).NotTo(BeNil(), exhaust)
+		Expect(matches[3]).To(Equal("true"), "the write failed with %q instead of running out of disk", matches[2])
+
+		written, err := strconv.Atoi(matches[1])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(200 + written).To(BeNumerically("~", diskQuotaMB, diskQuotaMarginMB))
+
+		By("observing the full disk reported by `cf app`")
+		Eventually(func() float64 {
+			return diskUsageMB(appName)
+		}, Config.DefaultTimeoutDuration(), "5s").Should(BeNumerically("~", diskQuotaMB, diskQuotaMarginMB))
+
+		By("observing that the instance keeps running with a full disk")
+		Expect(helpers.CurlAppRoot(Config, appName)).To(ContainSubstring("go, world"))
+
+		events := cf.Cf("events", appName).Wait(Config.DefaultTimeoutDuration())
+		Expect(events).To(Exit(0))
+		Expect(events.Out.Contents()).NotTo(ContainSubstring("app.crash"))
+	})
+})
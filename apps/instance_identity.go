@@ -0,0 +1,93 @@
+package apps
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/app_helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/certificates"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/skip_messages"
+)
+
+// instanceIdentity is the instance identity certificate as parsed by the
+// Golang asset, next to the environment of the instance that serves it.
+type instanceIdentity struct {
+	CommonName          string    `json:"common_name"`
+	OrganizationalUnits []string  `json:"organizational_units"`
+	DNSNames            []string  `json:"dns_names"`
+	IPAddresses         []string  `json:"ip_addresses"`
+	NotBefore           time.Time `json:"not_before"`
+	NotAfter            time.Time `json:"not_after"`
+	Now                 time.Time `json:"now"`
+
+	InstanceGuid       string `json:"instance_guid"`
+	InstanceInternalIP string `json:"instance_internal_ip"`
+}
+
+func guid(args ...string) string {
+	session := cf.Cf(append(args, "--guid")...).Wait(Config.DefaultTimeoutDuration())
+	Expect(session).To(Exit(0))
+	return strings.TrimSpace(string(session.Out.Contents()))
+}
+
+var _ = AppsDescribe("Instance identity credentials", func() {
+	var appName string
+
+	BeforeEach(func() {
+		if Config.GetBackend() != "diego" {
+			Skip(skip_messages.SkipDiegoMessage)
+		}
+		if !Config.GetIncludeInstanceIdentity() {
+			Skip(`Skipping this test because Config.IncludeInstanceIdentity is set to 'false'.`)
+		}
+
+		appName = random_name.CATSRandomName("APP")
+		Expect(cf.Cf("push",
+			appName,
+			"--no-start",
+			"-b", Config.GetGoBuildpackName(),
+			"-p", assets.NewAssets().Golang,
+			"-m", DEFAULT_MEMORY_LIMIT,
+			"-d", Config.GetAppsDomain()).Wait(Config.CfPushTimeoutDuration())).To(Exit(0))
+		app_helpers.SetBackend(appName)
+		Expect(cf.Cf("start", appName).Wait(Config.CfPushTimeoutDuration())).To(Exit(0))
+	})
+
+	AfterEach(func() {
+		app_helpers.AppReport(appName, Config.DefaultTimeoutDuration())
+		Expect(cf.Cf("delete", appName, "-f", "-r").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+	})
+
+	It("gives the instance a certificate for its app, space and org", func() {
+		var identity instanceIdentity
+		Eventually(func() error {
+			return json.Unmarshal([]byte(helpers.CurlApp(Config, appName, "/instance-identity")), &identity)
+		}, Config.DefaultTimeoutDuration()).Should(Succeed())
+
+		appGuid := guid("app", appName)
+		spaceGuid := guid("space", TestSetup.RegularUserContext().Space)
+		orgGuid := guid("org", TestSetup.RegularUserContext().Org)
+
+		for _, unit := range certificates.InstanceIdentityOrganizationalUnits(appGuid, spaceGuid, orgGuid) {
+			Expect(identity.OrganizationalUnits).To(ContainElement(unit))
+		}
+
+		Expect(identity.InstanceGuid).NotTo(BeEmpty())
+		Expect(identity.CommonName).To(Equal(identity.InstanceGuid))
+		Expect(identity.DNSNames).To(ContainElement(identity.InstanceGuid))
+		Expect(identity.IPAddresses).To(ContainElement(identity.InstanceInternalIP))
+
+		Expect(identity.NotBefore).To(BeTemporally("<=", identity.Now))
+		Expect(identity.NotAfter).To(BeTemporally(">", identity.Now))
+	})
+})
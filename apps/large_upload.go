@@ -0,0 +1,208 @@
+package apps
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/app_helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/router_response"
+)
+
+const (
+	largeUploadBytes = 300 * 1024 * 1024
+	slowUploadBytes  = 1024 * 1024
+	slowUploadRate   = 16 * 1024
+	largeHeaderBytes = 60 * 1024
+)
+
+// uploadReceipt is the response of the go-upload asset.
+type uploadReceipt struct {
+	Bytes         int64  `json:"bytes"`
+	Sha256        string `json:"sha256"`
+	ContentLength int64  `json:"content_length"`
+	Chunked       bool   `json:"chunked"`
+}
+
+// uploadResponse is the outcome of an upload through the router.
+type uploadResponse struct {
+	router_response.Response
+	Receipt uploadReceipt
+}
+
+// writeUploadFile writes size pseudo-random bytes to a file in dir and returns
+// its path and SHA-256 hash.
+func writeUploadFile(dir string, size int64) (string, string) {
+	file, err := ioutil.TempFile(dir, "upload")
+	Expect(err).NotTo(HaveOccurred())
+	defer file.Close()
+
+	hash := sha256.New()
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	_, err = io.CopyN(io.MultiWriter(file, hash), random, size)
+	Expect(err).NotTo(HaveOccurred())
+
+	return file.Name(), hex.EncodeToString(hash.Sum(nil))
+}
+
+// uploadThroughRouter posts the file to the go-upload asset with the given
+// curl arguments.
+func uploadThroughRouter(appName, path string, timeout time.Duration, args ...string) uploadResponse {
+	args = append([]string{"-X", "POST", "--data-binary", "@" + path}, args...)
+	response := uploadResponse{Response: router_response.Curl(appName, "/upload", timeout, args...)}
+	if response.Status() == 200 {
+		Expect(json.Unmarshal([]byte(response.Body), &response.Receipt)).To(Succeed())
+	}
+	return response
+}
+
+var _ = AppsDescribe("Large_upload", func() {
+	var (
+		appName string
+		tmpDir  string
+	)
+
+	BeforeEach(func() {
+		appName = random_name.CATSRandomName("APP")
+		Expect(cf.Cf("push", appName, "--no-start", "-b", Config.GetGoBuildpackName(), "-m", DEFAULT_MEMORY_LIMIT, "-p", assets.NewAssets().GoUpload, "-d", Config.GetAppsDomain()).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		app_helpers.SetBackend(appName)
+		Expect(cf.Cf("start", appName).Wait(Config.CfPushTimeoutDuration())).To(Exit(0))
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "large-upload")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+		app_helpers.AppReport(appName, Config.DefaultTimeoutDuration())
+
+		Expect(cf.Cf("delete", appName, "-f", "-r").Wait(Config.CfPushTimeoutDuration())).To(Exit(0))
+	})
+
+	Context("with a large request body", func() {
+		var (
+			path string
+			sum  string
+		)
+
+		BeforeEach(func() {
+			path, sum = writeUploadFile(tmpDir, largeUploadBytes)
+		})
+
+		It("streams the body to the app", func() {
+			response := uploadThroughRouter(appName, path, Config.CfPushTimeoutDuration(), "-H", "Expect:")
+
+			Expect(response.Status()).To(Equal(200))
+			Expect(response.Receipt).To(Equal(uploadReceipt{
+				Bytes:         largeUploadBytes,
+				Sha256:        sum,
+				ContentLength: largeUploadBytes,
+			}))
+		})
+
+		It("streams a chunked body to the app", func() {
+			response := uploadThroughRouter(appName, path, Config.CfPushTimeoutDuration(), "-H", "Expect:", "-H", "Transfer-Encoding: chunked")
+
+			Expect(response.Status()).To(Equal(200))
+			Expect(response.Receipt.Bytes).To(Equal(int64(largeUploadBytes)))
+			Expect(response.Receipt.Sha256).To(Equal(sum))
+			Expect(response.Receipt.Chunked).To(BeTrue())
+		})
+
+		It("continues the request when the client expects 100-continue", func() {
+			response := uploadThroughRouter(appName, path, Config.CfPushTimeoutDuration(), "-H", "Expect: 100-continue")
+
+			Expect(response.Statuses).To(Equal([]int{100, 200}))
+			Expect(response.Receipt.Bytes).To(Equal(int64(largeUploadBytes)))
+			Expect(response.Receipt.Sha256).To(Equal(sum))
+		})
+	})
+
+	Context("with a slow client", func() {
+		It("streams the body to the app as it arrives", func() {
+			path, sum := writeUploadFile(tmpDir, slowUploadBytes)
+			duration := time.Duration(slowUploadBytes/slowUploadRate) * time.Second
+
+			response := uploadThroughRouter(appName, path, 2*duration, "--limit-rate", strconv.Itoa(slowUploadRate), "-H", "Expect:")
+
+			Expect(response.Status()).To(Equal(200))
+			Expect(response.Receipt.Bytes).To(Equal(int64(slowUploadBytes)))
+			Expect(response.Receipt.Sha256).To(Equal(sum))
+		})
+
+		It("fails the request when the upload outlasts the request timeout of the router", func() {
+			if Config.RouterRequestTimeoutDuration() == 0 {
+				Skip(`Skipping this test because Config.RouterRequestTimeout is not set.`)
+			}
+
+			duration := Config.RouterRequestTimeoutDuration() + time.Minute
+			path, _ := writeUploadFile(tmpDir, int64(duration.Seconds())*slowUploadRate)
+
+			response := uploadThroughRouter(appName, path, 2*duration, "--limit-rate", strconv.Itoa(slowUploadRate), "-H", "Expect:")
+
+			Expect(response.Status()).To(Equal(504))
+			Expect(response.RouterError).To(Equal("endpoint_failure"))
+		})
+	})
+
+	Context("with large request headers", func() {
+		var maxHeaderBytes int
+
+		BeforeEach(func() {
+			maxHeaderBytes = Config.GetRouterMaxHeaderBytes()
+			if maxHeaderBytes == 0 {
+				Skip(`Skipping this test because Config.RouterMaxHeaderBytes is not set.`)
+			}
+		})
+
+		// headers returns curl arguments for headers whose values add up to
+		// total bytes, none of them longer than largeHeaderBytes.
+		headers := func(total int) []string {
+			args := []string{}
+			for i := 0; total > 0; i++ {
+				size := largeHeaderBytes
+				if total < size {
+					size = total
+				}
+				args = append(args, "-H", fmt.Sprintf("X-Cats-Padding-%d: %s", i, strings.Repeat("x", size)))
+				total -= size
+			}
+			return args
+		}
+
+		It("accepts headers within the limit of the router", func() {
+			path, sum := writeUploadFile(tmpDir, 1024)
+
+			response := uploadThroughRouter(appName, path, Config.DefaultTimeoutDuration(), headers(maxHeaderBytes/2)...)
+
+			Expect(response.Status()).To(Equal(200))
+			Expect(response.Receipt.Sha256).To(Equal(sum))
+		})
+
+		It("rejects headers beyond the limit of the router with 431", func() {
+			path, _ := writeUploadFile(tmpDir, 1024)
+
+			response := uploadThroughRouter(appName, path, Config.DefaultTimeoutDuration(), headers(maxHeaderBytes+largeHeaderBytes)...)
+
+			Expect(response.Status()).To(Equal(431))
+		})
+	})
+})
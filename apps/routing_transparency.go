@@ -1,6 +1,10 @@
 package apps
 
 import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
 	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -13,6 +17,19 @@ import (
 	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
 )
 
+const (
+	uuidPattern     = `^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`
+	b3TraceIdLength = 16
+)
+
+// requestHeaders returns the headers that the Golang asset received for a
+// request sent through the router with the given curl arguments.
+func requestHeaders(appName string, args ...string) http.Header {
+	var headers http.Header
+	Expect(json.Unmarshal([]byte(helpers.CurlApp(Config, appName, "/headers", args...)), &headers)).To(Succeed())
+	return headers
+}
+
 var _ = AppsDescribe("Routing Transparency", func() {
 	var appName string
 
@@ -53,4 +70,85 @@ var _ = AppsDescribe("Routing Transparency", func() {
 		Expect(curlResponse).To(ContainSubstring("/requesturi/!~^'()$\""))
 		Expect(curlResponse).To(ContainSubstring("Query String is [!'()$]"))
 	})
+
+	Describe("request headers", func() {
+		BeforeEach(func() {
+			Eventually(func() string {
+				return helpers.CurlAppRoot(Config, appName)
+			}, Config.DefaultTimeoutDuration()).Should(ContainSubstring("go, world"))
+		})
+
+		It("preserves the Host header", func() {
+			Expect(requestHeaders(appName).Get("Host")).To(Equal(appName + "." + Config.GetAppsDomain()))
+		})
+
+		It("adds the X-Forwarded and request tracking headers", func() {
+			headers := requestHeaders(appName)
+
+			Expect(headers.Get("X-Forwarded-For")).NotTo(BeEmpty())
+			Expect(headers.Get("X-Forwarded-Proto")).To(Equal(strings.TrimSuffix(Config.Protocol(), "://")))
+			Expect(headers.Get("X-Request-Start")).To(MatchRegexp(`^\d{13}$`))
+			Expect(headers.Get("X-Vcap-Request-Id")).To(MatchRegexp(uuidPattern))
+		})
+
+		It("gives each request its own X-Vcap-Request-Id", func() {
+			Expect(requestHeaders(appName).Get("X-Vcap-Request-Id")).NotTo(Equal(requestHeaders(appName).Get("X-Vcap-Request-Id")))
+		})
+
+		Context("when the client supplies the headers the router sets", func() {
+			It("appends to X-Forwarded-For instead of trusting it", func() {
+				forwardedFor := requestHeaders(appName, "-H", "X-Forwarded-For: 192.0.2.1").Get("X-Forwarded-For")
+				Expect(forwardedFor).To(HavePrefix("192.0.2.1, "))
+			})
+
+			It("replaces X-Vcap-Request-Id", func() {
+				requestId := requestHeaders(appName, "-H", "X-Vcap-Request-Id: spoofed").Get("X-Vcap-Request-Id")
+
+				Expect(requestId).NotTo(ContainSubstring("spoofed"))
+				Expect(requestId).To(MatchRegexp(uuidPattern))
+			})
+		})
+
+		It("strips hop-by-hop headers and passes on end-to-end headers", func() {
+			headers := requestHeaders(appName,
+				"-H", "Connection: keep-alive, X-Cats-Hop-By-Hop",
+				"-H", "X-Cats-Hop-By-Hop: dropped",
+				"-H", "Keep-Alive: timeout=5",
+				"-H", "Proxy-Connection: keep-alive",
+				"-H", "X-Cats-End-To-End: kept",
+			)
+
+			Expect(headers).NotTo(HaveKey("X-Cats-Hop-By-Hop"))
+			Expect(headers).NotTo(HaveKey("Keep-Alive"))
+			Expect(headers).NotTo(HaveKey("Proxy-Connection"))
+			Expect(headers.Get("X-Cats-End-To-End")).To(Equal("kept"))
+		})
+
+		Context("when zipkin tracing is enabled", func() {
+			BeforeEach(func() {
+				if !Config.GetIncludeZipkin() {
+					Skip(`Skipping this test because Config.IncludeZipkin is set to 'false'`)
+				}
+			})
+
+			It("starts a trace for requests without one", func() {
+				headers := requestHeaders(appName)
+
+				Expect(headers.Get("X-B3-TraceId")).To(MatchRegexp(`^[0-9a-f]{%d}$`, b3TraceIdLength))
+				Expect(headers.Get("X-B3-SpanId")).To(MatchRegexp(`^[0-9a-f]{%d}$`, b3TraceIdLength))
+			})
+
+			It("continues the trace of requests that have one", func() {
+				traceId := "fee1f7ba6aeec41c"
+
+				headers := requestHeaders(appName,
+					"-H", "X-B3-TraceId: "+traceId,
+					"-H", "X-B3-SpanId: "+traceId,
+				)
+
+				Expect(headers.Get("X-B3-TraceId")).To(Equal(traceId))
+				Expect(headers.Get("X-B3-SpanId")).NotTo(BeEmpty())
+			})
+		})
+	})
 })
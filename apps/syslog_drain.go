@@ -1,13 +1,11 @@
 package apps
 
 import (
-	"regexp"
 	"time"
 
 	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
 
 	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
-	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
 	"github.com/cloudfoundry/cf-acceptance-tests/helpers/app_helpers"
 	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
 	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
@@ -58,7 +56,7 @@ var _ = AppsDescribe("Logging", func() {
 		})
 
 		It("forwards app messages to registered syslog drains", func() {
-			syslogDrainURL := "syslog://" + getSyslogDrainAddress(listenerAppName)
+			syslogDrainURL := "syslog://" + app_helpers.GetSyslogDrainAddress(listenerAppName)
 
 			Eventually(cf.Cf("cups", serviceName, "-l", syslogDrainURL), Config.DefaultTimeoutDuration()).Should(Exit(0), "Failed to create syslog drain service")
 			Eventually(cf.Cf("bind-service", logWriterAppName, serviceName), Config.DefaultTimeoutDuration()).Should(Exit(0), "Failed to bind service")
@@ -66,37 +64,9 @@ var _ = AppsDescribe("Logging", func() {
 
 			logs = cf.Cf("logs", listenerAppName)
 			randomMessage := random_name.CATSRandomName("RANDOM-MESSAGE")
-			go writeLogsUntilInterrupted(interrupt, randomMessage, logWriterAppName)
+			go app_helpers.WriteLogsUntilInterrupted(interrupt, logWriterAppName, "/log/"+randomMessage)
 
 			Eventually(logs, Config.DefaultTimeoutDuration()+1*time.Minute).Should(Say(randomMessage))
 		})
 	})
 })
-
-func getSyslogDrainAddress(appName string) string {
-	var address []byte
-
-	Eventually(func() []byte {
-		re, err := regexp.Compile("ADDRESS: \\|(.*)\\|")
-		Expect(err).NotTo(HaveOccurred())
-
-		logs := cf.Cf("logs", appName, "--recent").Wait(Config.DefaultTimeoutDuration())
-		address = re.FindSubmatch(logs.Out.Contents())[1]
-		return address
-	}, Config.DefaultTimeoutDuration()).Should(Not(BeNil()))
-
-	return string(address)
-}
-
-func writeLogsUntilInterrupted(interrupt chan struct{}, randomMessage string, logWriterAppName string) {
-	defer GinkgoRecover()
-	for {
-		select {
-		case <-interrupt:
-			return
-		default:
-			helpers.CurlAppWithTimeout(Config, logWriterAppName, "/log/"+randomMessage, Config.DefaultTimeoutDuration())
-			time.Sleep(3 * time.Second)
-		}
-	}
-}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+	"sync"
+)
+
+const (
+	forwardedURLHeader   = "X-CF-Forwarded-Url"
+	proxySignatureHeader = "X-CF-Proxy-Signature"
+	proxyMetadataHeader  = "X-CF-Proxy-Metadata"
+
+	// modeHeader is set by the client of the app the route service is bound
+	// to. The router passes it through to the route service.
+	modeHeader = "X-Cats-Route-Service-Mode"
+)
+
+// signature is the pair of headers the router signs a forwarded request with.
+type signature struct {
+	Signature string
+	Metadata  string
+}
+
+type routeService struct {
+	proxy *httputil.ReverseProxy
+
+	mutex sync.Mutex
+	// firstSignatures holds the first signature seen for each forwarded URL,
+	// so that it can be replayed once it has expired.
+	firstSignatures map[string]signature
+}
+
+func main() {
+	transport := &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: os.Getenv("SKIP_SSL_VALIDATION") == "true"},
+	}
+
+	service := &routeService{
+		firstSignatures: map[string]signature{},
+		proxy: &httputil.ReverseProxy{
+			Director:  func(*http.Request) {},
+			Transport: transport,
+		},
+	}
+
+	fmt.Println("listening...")
+	err := http.ListenAndServe(":"+os.Getenv("PORT"), service)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (s *routeService) ServeHTTP(res http.ResponseWriter, req *http.Request) {
+	forwardedURL := req.Header.Get(forwardedURLHeader)
+	if forwardedURL == "" {
+		http.Error(res, "not a route service request: "+forwardedURLHeader+" is missing", http.StatusBadRequest)
+		return
+	}
+
+	received := signature{
+		Signature: req.Header.Get(proxySignatureHeader),
+		Metadata:  req.Header.Get(proxyMetadataHeader),
+	}
+	fmt.Printf("Forwarding to %s\n", forwardedURL)
+
+	s.mutex.Lock()
+	first, seen := s.firstSignatures[forwardedURL]
+	if !seen {
+		first = received
+		s.firstSignatures[forwardedURL] = received
+	}
+	s.mutex.Unlock()
+
+	switch req.Header.Get(modeHeader) {
+	case "echo":
+		echoHeaders(res, req)
+		return
+	case "fail":
+		http.Error(res, "route service unavailable", http.StatusServiceUnavailable)
+		return
+	case "tamper":
+		received.Signature = tamper(received.Signature)
+	case "replay":
+		received = first
+	}
+
+	target, err := url.Parse(forwardedURL)
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	req.URL = target
+	req.Host = target.Host
+	req.Header.Set(proxySignatureHeader, received.Signature)
+	req.Header.Set(proxyMetadataHeader, received.Metadata)
+	s.proxy.ServeHTTP(res, req)
+}
+
+// echoHeaders responds with the route service headers of the request instead
+// of forwarding it.
+func echoHeaders(res http.ResponseWriter, req *http.Request) {
+	res.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(res).Encode(map[string]string{
+		forwardedURLHeader:   req.Header.Get(forwardedURLHeader),
+		proxySignatureHeader: req.Header.Get(proxySignatureHeader),
+		proxyMetadataHeader:  req.Header.Get(proxyMetadataHeader),
+	})
+}
+
+// tamper flips a bit of the encrypted signature, so that it still decodes
+// but no longer authenticates.
+func tamper(encoded string) string {
+	decoded, err := base64.URLEncoding.DecodeString(encoded)
+	if err != nil || len(decoded) == 0 {
+		return encoded + "tampered"
+	}
+	decoded[len(decoded)-1] ^= 1
+	return base64.URLEncoding.EncodeToString(decoded)
+}
@@ -0,0 +1,468 @@
+// Package broker is a Go port of the configurable CATS service broker in
+// assets/service_broker. It serves the same /config API and picks responses
+// from the same data.json behaviors, keyed by plan id with a "default"
+// fallback, so it can be pushed as an app or run in-process with httptest.
+package broker
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type serviceInstance struct {
+	ProvisionData map[string]interface{} `json:"provision_data"`
+	FetchCount    int                    `json:"fetch_count"`
+	Deleted       bool                   `json:"deleted"`
+}
+
+func (i *serviceInstance) planID() string {
+	planID, _ := i.ProvisionData["plan_id"].(string)
+	return planID
+}
+
+type serviceBinding struct {
+	BindingData map[string]interface{} `json:"binding_data"`
+	InstanceID  string                 `json:"instance_id"`
+	FetchCount  int                    `json:"fetch_count"`
+	Deleted     bool                   `json:"deleted"`
+}
+
+func (b *serviceBinding) planID() string {
+	planID, _ := b.BindingData["plan_id"].(string)
+	return planID
+}
+
+// Request is an entry in the broker's journal of the Open Service Broker API
+// requests it has received.
+type Request struct {
+	Method  string              `json:"method"`
+	Path    string              `json:"path"`
+	Query   map[string][]string `json:"query"`
+	Headers map[string][]string `json:"headers"`
+	Body    string              `json:"body"`
+}
+
+type Broker struct {
+	mutex sync.Mutex
+	log   *log.Logger
+
+	initialData []byte
+	data        map[string]interface{}
+	instances   map[string]*serviceInstance
+	bindings    map[string]*serviceBinding
+	requests    []Request
+}
+
+// New returns a broker configured from the contents of a data.json file.
+// Requests and responses are logged to logOutput.
+func New(initialData []byte, logOutput io.Writer) (*Broker, error) {
+	b := &Broker{
+		log:         log.New(logOutput, "", 0),
+		initialData: initialData,
+	}
+	if err := b.reset(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *Broker) reset() error {
+	var data map[string]interface{}
+	if err := json.Unmarshal(b.initialData, &data); err != nil {
+		return err
+	}
+
+	delete(data, "service_instances")
+	delete(data, "service_bindings")
+
+	b.data = data
+	b.instances = map[string]*serviceInstance{}
+	b.bindings = map[string]*serviceBinding{}
+	b.requests = []Request{}
+	return nil
+}
+
+func (b *Broker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		b.respondWithError(w, r, err)
+		return
+	}
+	b.logRequest(r, body)
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	path := strings.Trim(r.URL.Path, "/")
+	segments := strings.Split(path, "/")
+
+	if segments[0] == "v2" {
+		b.requests = append(b.requests, Request{
+			Method:  r.Method,
+			Path:    r.URL.Path,
+			Query:   r.URL.Query(),
+			Headers: r.Header,
+			Body:    string(body),
+		})
+	}
+
+	switch {
+	case r.Method == "GET" && path == "v2/catalog":
+		b.catalog(w, r)
+	case len(segments) == 3 && segments[0] == "v2" && segments[1] == "service_instances":
+		b.serviceInstance(w, r, segments[2], body)
+	case len(segments) == 4 && segments[0] == "v2" && segments[1] == "service_instances" && segments[3] == "last_operation" && r.Method == "GET":
+		b.lastOperation(w, r, segments[2])
+	case len(segments) == 5 && segments[0] == "v2" && segments[1] == "service_instances" && segments[3] == "service_bindings":
+		b.serviceBinding(w, r, segments[2], segments[4], body)
+	case len(segments) == 6 && segments[0] == "v2" && segments[1] == "service_instances" && segments[3] == "service_bindings" && segments[5] == "last_operation" && r.Method == "GET":
+		b.bindingLastOperation(w, r, segments[4])
+	case r.Method == "GET" && path == "config/all":
+		b.respondWithJSON(w, r, http.StatusOK, b.allData())
+	case r.Method == "GET" && path == "config":
+		b.respondWithJSON(w, r, http.StatusOK, b.data)
+	case r.Method == "POST" && path == "config":
+		b.configure(w, r, body)
+	case r.Method == "GET" && path == "requests":
+		b.respondWithJSON(w, r, http.StatusOK, b.requests)
+	case r.Method == "DELETE" && path == "requests":
+		b.requests = []Request{}
+		b.respondWithJSON(w, r, http.StatusOK, b.requests)
+	case r.Method == "POST" && path == "config/reset":
+		if err := b.reset(); err != nil {
+			b.respondWithError(w, r, err)
+			return
+		}
+		b.respondWithJSON(w, r, http.StatusOK, b.data)
+	default:
+		b.respond(w, r, http.StatusNotFound, []byte(`{"error":"not found"}`))
+	}
+}
+
+func (b *Broker) catalog(w http.ResponseWriter, r *http.Request) {
+	behaviors, _ := b.data["behaviors"].(map[string]interface{})
+	behavior, ok := behaviors["catalog"].(map[string]interface{})
+	if !ok {
+		b.respondWithError(w, r, fmt.Errorf("Behavior object is missing key: catalog"))
+		return
+	}
+	b.respondWithBehavior(w, r, behavior)
+}
+
+func (b *Broker) serviceInstance(w http.ResponseWriter, r *http.Request, instanceID string, body []byte) {
+	switch r.Method {
+	case "PUT":
+		var provisionData map[string]interface{}
+		if err := json.Unmarshal(body, &provisionData); err != nil {
+			b.respondWithError(w, r, err)
+			return
+		}
+
+		instance := &serviceInstance{ProvisionData: provisionData}
+		b.instances[instanceID] = instance
+		b.respondWithBehaviorFor(w, r, "provision", instance.planID())
+
+	case "PATCH":
+		var updateData map[string]interface{}
+		if err := json.Unmarshal(body, &updateData); err != nil {
+			b.respondWithError(w, r, err)
+			return
+		}
+
+		planID, _ := updateData["plan_id"].(string)
+		behavior, err := b.behaviorFor("update", planID)
+		if err != nil {
+			b.respondWithError(w, r, err)
+			return
+		}
+
+		status, _ := behavior["status"].(float64)
+		if instance, ok := b.instances[instanceID]; ok && (status == 200 || status == 202) {
+			for key, value := range updateData {
+				instance.ProvisionData[key] = value
+			}
+			instance.FetchCount = 0
+		}
+		b.respondWithBehavior(w, r, behavior)
+
+	case "DELETE":
+		planID := ""
+		if instance, ok := b.instances[instanceID]; ok {
+			instance.Deleted = true
+			instance.FetchCount = 0
+			planID = instance.planID()
+		}
+		b.respondWithBehaviorFor(w, r, "deprovision", planID)
+
+	default:
+		b.respond(w, r, http.StatusMethodNotAllowed, []byte(`{}`))
+	}
+}
+
+func (b *Broker) lastOperation(w http.ResponseWriter, r *http.Request, instanceID string) {
+	instance, ok := b.instances[instanceID]
+	if !ok {
+		b.respondWithJSON(w, r, http.StatusOK, map[string]string{
+			"state":       "failed",
+			"description": fmt.Sprintf("Broker could not find service instance by the given id %s", instanceID),
+		})
+		return
+	}
+
+	instance.FetchCount++
+	state := "in_progress"
+	if instance.FetchCount > b.maxFetchServiceInstanceRequests() {
+		state = "finished"
+	}
+
+	fetch, err := b.behaviorFor("fetch", instance.planID())
+	if err != nil {
+		b.respondWithError(w, r, err)
+		return
+	}
+
+	behavior, ok := fetch[state].(map[string]interface{})
+	if !ok {
+		b.respondWithError(w, r, fmt.Errorf("Behavior for fetch is missing response for state %s", state))
+		return
+	}
+
+	b.sleep(behavior)
+	b.respondFromConfig(w, r, behavior)
+}
+
+func (b *Broker) serviceBinding(w http.ResponseWriter, r *http.Request, instanceID, bindingID string, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case "GET":
+		binding, ok := b.bindings[bindingID]
+		if !ok || binding.Deleted {
+			b.respond(w, r, http.StatusNotFound, []byte(`{}`))
+			return
+		}
+		b.respondWithBehaviorFor(w, r, "fetch_service_binding", binding.planID())
+
+	case "PUT":
+		var bindingData map[string]interface{}
+		if err := json.Unmarshal(body, &bindingData); err != nil {
+			b.respondWithError(w, r, err)
+			return
+		}
+
+		binding := &serviceBinding{BindingData: bindingData, InstanceID: instanceID}
+		b.bindings[bindingID] = binding
+		b.respondWithBehaviorFor(w, r, "bind", binding.planID())
+
+	case "DELETE":
+		planID := ""
+		if binding, ok := b.bindings[bindingID]; ok {
+			binding.Deleted = true
+			binding.FetchCount = 0
+			planID = binding.planID()
+		}
+		b.respondWithBehaviorFor(w, r, "unbind", planID)
+
+	default:
+		b.respond(w, r, http.StatusMethodNotAllowed, []byte(`{}`))
+	}
+}
+
+// bindingLastOperation reports the progress of an asynchronous bind or unbind
+// the same way lastOperation does for service instances. A binding that has
+// finished being deleted is gone.
+func (b *Broker) bindingLastOperation(w http.ResponseWriter, r *http.Request, bindingID string) {
+	binding, ok := b.bindings[bindingID]
+	if !ok {
+		b.respond(w, r, http.StatusGone, []byte(`{}`))
+		return
+	}
+
+	binding.FetchCount++
+	state := "in_progress"
+	if binding.FetchCount > b.maxFetchServiceInstanceRequests() {
+		state = "finished"
+	}
+
+	if binding.Deleted && state == "finished" {
+		delete(b.bindings, bindingID)
+		b.respond(w, r, http.StatusGone, []byte(`{}`))
+		return
+	}
+
+	fetch, err := b.behaviorFor("fetch_service_binding_last_operation", binding.planID())
+	if err != nil {
+		b.respondWithError(w, r, err)
+		return
+	}
+
+	behavior, ok := fetch[state].(map[string]interface{})
+	if !ok {
+		b.respondWithError(w, r, fmt.Errorf("Behavior for fetch_service_binding_last_operation is missing response for state %s", state))
+		return
+	}
+
+	b.sleep(behavior)
+	b.respondFromConfig(w, r, behavior)
+}
+
+// configure merges a partial configuration into the current one. Top-level
+// objects such as "behaviors" are merged one level deep, so posting a single
+// behavior replaces only that behavior.
+func (b *Broker) configure(w http.ResponseWriter, r *http.Request, body []byte) {
+	var update map[string]interface{}
+	if err := json.Unmarshal(body, &update); err != nil {
+		b.respondWithError(w, r, err)
+		return
+	}
+
+	if instances, ok := update["service_instances"]; ok {
+		encoded, _ := json.Marshal(instances)
+		parsed := map[string]*serviceInstance{}
+		if err := json.Unmarshal(encoded, &parsed); err != nil {
+			b.respondWithError(w, r, err)
+			return
+		}
+		for id, instance := range parsed {
+			b.instances[id] = instance
+		}
+		delete(update, "service_instances")
+	}
+	delete(update, "service_bindings")
+
+	for key, value := range update {
+		existing, existingIsObject := b.data[key].(map[string]interface{})
+		updated, updatedIsObject := value.(map[string]interface{})
+		if existingIsObject && updatedIsObject {
+			for k, v := range updated {
+				existing[k] = v
+			}
+		} else {
+			b.data[key] = value
+		}
+	}
+
+	b.respondWithJSON(w, r, http.StatusOK, b.data)
+}
+
+func (b *Broker) allData() map[string]interface{} {
+	all := map[string]interface{}{}
+	for key, value := range b.data {
+		all[key] = value
+	}
+	all["service_instances"] = b.instances
+	all["service_bindings"] = b.bindings
+	return all
+}
+
+func (b *Broker) maxFetchServiceInstanceRequests() int {
+	if max, ok := b.data["max_fetch_service_instance_requests"].(float64); ok {
+		return int(max)
+	}
+	return 1
+}
+
+func (b *Broker) behaviorFor(action, planID string) (map[string]interface{}, error) {
+	behaviors, _ := b.data["behaviors"].(map[string]interface{})
+	plans, ok := behaviors[action].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("Behavior object is missing key: %s (tried to lookup plan_id %s)", action, planID)
+	}
+
+	if behavior, ok := plans[planID].(map[string]interface{}); ok && planID != "" {
+		return behavior, nil
+	}
+
+	b.log.Printf("INFO: Could not find response for plan id: %s", planID)
+	if behavior, ok := plans["default"].(map[string]interface{}); ok {
+		return behavior, nil
+	}
+	return nil, fmt.Errorf("Behavior for %s is missing response for plan_id %s and default response.", action, planID)
+}
+
+func (b *Broker) respondWithBehaviorFor(w http.ResponseWriter, r *http.Request, action, planID string) {
+	behavior, err := b.behaviorFor(action, planID)
+	if err != nil {
+		b.respondWithError(w, r, err)
+		return
+	}
+	b.respondWithBehavior(w, r, behavior)
+}
+
+func (b *Broker) respondWithBehavior(w http.ResponseWriter, r *http.Request, behavior map[string]interface{}) {
+	b.sleep(behavior)
+
+	asyncOnly, _ := behavior["async_only"].(bool)
+	if asyncOnly && r.URL.Query().Get("accepts_incomplete") != "true" {
+		b.respondWithJSON(w, r, 422, map[string]string{
+			"error":       "AsyncRequired",
+			"description": "This service plan requires client support for asynchronous service operations.",
+		})
+		return
+	}
+
+	b.respondFromConfig(w, r, behavior)
+}
+
+func (b *Broker) respondFromConfig(w http.ResponseWriter, r *http.Request, behavior map[string]interface{}) {
+	status := http.StatusOK
+	if configured, ok := behavior["status"].(float64); ok {
+		status = int(configured)
+	}
+
+	if body, ok := behavior["body"]; ok {
+		b.respondWithJSON(w, r, status, body)
+		return
+	}
+
+	rawBody, _ := behavior["raw_body"].(string)
+	b.respond(w, r, status, []byte(rawBody))
+}
+
+func (b *Broker) respondWithError(w http.ResponseWriter, r *http.Request, err error) {
+	b.respondWithJSON(w, r, http.StatusInternalServerError, map[string]interface{}{
+		"error":     true,
+		"message":   err.Error(),
+		"path":      r.URL.String(),
+		"timestamp": time.Now(),
+		"type":      "500",
+	})
+}
+
+func (b *Broker) respondWithJSON(w http.ResponseWriter, r *http.Request, status int, body interface{}) {
+	encoded, err := json.MarshalIndent(body, "", "  ")
+	if err != nil {
+		status = http.StatusInternalServerError
+		encoded = []byte(fmt.Sprintf(`{"error":true,"message":%q}`, err.Error()))
+	}
+	b.respond(w, r, status, encoded)
+}
+
+func (b *Broker) respond(w http.ResponseWriter, r *http.Request, status int, body []byte) {
+	b.log.Printf("INFO: Response: status=%d, body=%s", status, body)
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+func (b *Broker) logRequest(r *http.Request, body []byte) {
+	b.log.Printf("INFO: %s %s %s", r.Method, r.URL.Path, r.URL.RawQuery)
+	b.log.Printf("INFO: Request headers: %v", r.Header)
+	b.log.Printf("INFO: Request body: %s", body)
+}
+
+// sleep waits for the behavior's sleep_seconds without holding the lock, so
+// a slow response does not hold up other requests.
+func (b *Broker) sleep(behavior map[string]interface{}) {
+	if seconds, ok := behavior["sleep_seconds"].(float64); ok && seconds > 0 {
+		b.mutex.Unlock()
+		time.Sleep(time.Duration(seconds * float64(time.Second)))
+		b.mutex.Lock()
+	}
+}
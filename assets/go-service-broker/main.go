@@ -0,0 +1,26 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/assets/go-service-broker/broker"
+)
+
+func main() {
+	data, err := ioutil.ReadFile("data.json")
+	if err != nil {
+		panic(err)
+	}
+
+	serviceBroker, err := broker.New(data, os.Stdout)
+	if err != nil {
+		panic(err)
+	}
+
+	err = http.ListenAndServe(":"+os.Getenv("PORT"), serviceBroker)
+	if err != nil {
+		panic(err)
+	}
+}
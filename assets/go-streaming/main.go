@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// connections tracks the open WebSockets, so they can be closed cleanly when
+// the app is stopped.
+type connections struct {
+	mutex sync.Mutex
+	open  map[*websocket.Conn]bool
+}
+
+func (c *connections) add(conn *websocket.Conn) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.open[conn] = true
+}
+
+func (c *connections) remove(conn *websocket.Conn) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	delete(c.open, conn)
+}
+
+func (c *connections) closeAll() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	message := websocket.FormatCloseMessage(websocket.CloseGoingAway, "app is stopping")
+	for conn := range c.open {
+		conn.WriteControl(websocket.CloseMessage, message, time.Now().Add(time.Second))
+	}
+}
+
+var (
+	upgrader = websocket.Upgrader{
+		CheckOrigin: func(*http.Request) bool { return true },
+	}
+	websockets = &connections{open: map[*websocket.Conn]bool{}}
+	instance   = os.Getenv("CF_INSTANCE_INDEX")
+)
+
+func main() {
+	http.HandleFunc("/", hello)
+	http.HandleFunc("/websocket", echoWebSocket)
+	http.HandleFunc("/events", serverSentEvents)
+	http.HandleFunc("/stream", stream)
+
+	go closeWebSocketsOnStop()
+
+	fmt.Println("listening...")
+	err := http.ListenAndServe(":"+os.Getenv("PORT"), nil)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func hello(res http.ResponseWriter, req *http.Request) {
+	fmt.Fprintln(res, "go-streaming")
+}
+
+// closeWebSocketsOnStop sends a going-away close frame on every open
+// WebSocket when the app receives SIGTERM, then exits.
+func closeWebSocketsOnStop() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	<-signals
+
+	fmt.Println("stopping, closing websockets")
+	websockets.closeAll()
+	time.Sleep(time.Second)
+	os.Exit(0)
+}
+
+// echoWebSocket greets the client with the index of the instance, then sends
+// back every message it receives.
+func echoWebSocket(res http.ResponseWriter, req *http.Request) {
+	conn, err := upgrader.Upgrade(res, req, nil)
+	if err != nil {
+		fmt.Printf("upgrade failed: %s\n", err)
+		return
+	}
+	defer conn.Close()
+
+	websockets.add(conn)
+	defer websockets.remove(conn)
+
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("connected to instance "+instance)); err != nil {
+		return
+	}
+
+	for {
+		messageType, message, err := conn.ReadMessage()
+		if err != nil {
+			fmt.Printf("websocket closed: %s\n", err)
+			return
+		}
+		if err := conn.WriteMessage(messageType, message); err != nil {
+			return
+		}
+	}
+}
+
+// serverSentEvents sends ?count= events, ?interval_ms= apart.
+func serverSentEvents(res http.ResponseWriter, req *http.Request) {
+	flusher, ok := res.(http.Flusher)
+	if !ok {
+		http.Error(res, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	count, interval := streamParameters(req)
+	res.Header().Set("Content-Type", "text/event-stream")
+	res.Header().Set("Cache-Control", "no-cache")
+
+	for i := 0; i < count; i++ {
+		fmt.Fprintf(res, "id: %d\ndata: event %d\n\n", i, i)
+		flusher.Flush()
+		time.Sleep(interval)
+	}
+}
+
+// stream writes ?count= chunks, ?interval_ms= apart, using chunked transfer
+// encoding.
+func stream(res http.ResponseWriter, req *http.Request) {
+	flusher, ok := res.(http.Flusher)
+	if !ok {
+		http.Error(res, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	count, interval := streamParameters(req)
+	res.Header().Set("Content-Type", "text/plain")
+
+	for i := 0; i < count; i++ {
+		fmt.Fprintf(res, "chunk %d\n", i)
+		flusher.Flush()
+		time.Sleep(interval)
+	}
+}
+
+func streamParameters(req *http.Request) (int, time.Duration) {
+	count, err := strconv.Atoi(req.URL.Query().Get("count"))
+	if err != nil {
+		count = 5
+	}
+
+	intervalMs, err := strconv.Atoi(req.URL.Query().Get("interval_ms"))
+	if err != nil {
+		intervalMs = 1000
+	}
+	return count, time.Duration(intervalMs) * time.Millisecond
+}
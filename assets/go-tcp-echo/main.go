@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+)
+
+func main() {
+	listener, err := net.Listen("tcp", ":"+os.Getenv("PORT"))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("listening...")
+
+	instance := os.Getenv("CF_INSTANCE_INDEX")
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			fmt.Printf("accept failed: %s\n", err)
+			continue
+		}
+		go echo(conn, instance)
+	}
+}
+
+// echo writes every line it reads back to the connection, prefixed with the
+// index of the instance so that clients can tell instances apart.
+func echo(conn net.Conn, instance string) {
+	defer conn.Close()
+
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		if _, err := fmt.Fprintf(conn, "%s:%s\n", instance, scanner.Text()); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+var (
+	instance = os.Getenv("CF_INSTANCE_INDEX")
+	server   = &http.Server{}
+	listener net.Listener
+
+	// unlistened is closed once the app stops accepting connections.
+	unlistened = make(chan struct{})
+)
+
+func main() {
+	http.HandleFunc("/", hello)
+	http.HandleFunc("/misbehave", misbehave)
+
+	var err error
+	listener, err = net.Listen("tcp", ":"+os.Getenv("PORT"))
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("listening...")
+	err = server.Serve(listener)
+
+	select {
+	case <-unlistened:
+		select {}
+	default:
+		panic(err)
+	}
+}
+
+func hello(res http.ResponseWriter, req *http.Request) {
+	fmt.Fprintf(res, "instance %s\n", instance)
+}
+
+// misbehave fails the request in the way given by ?mode= when it is handled
+// by the instance given by ?index=, and answers like hello otherwise:
+//
+//	close     closes the connection without responding
+//	hang      responds after ?seconds=, 600 by default
+//	502       responds with 502 Bad Gateway
+//	exit      exits the app without responding
+//	unlisten  stops accepting connections without exiting
+func misbehave(res http.ResponseWriter, req *http.Request) {
+	if req.URL.Query().Get("index") != instance {
+		hello(res, req)
+		return
+	}
+
+	mode := req.URL.Query().Get("mode")
+	fmt.Printf("misbehaving with mode %q on %s %s\n", mode, req.Method, req.URL.Path)
+
+	switch mode {
+	case "close":
+		hijacker, ok := res.(http.Hijacker)
+		if !ok {
+			http.Error(res, "hijacking is not supported", http.StatusInternalServerError)
+			return
+		}
+		conn, _, err := hijacker.Hijack()
+		if err != nil {
+			http.Error(res, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		conn.Close()
+	case "hang":
+		seconds, err := strconv.Atoi(req.URL.Query().Get("seconds"))
+		if err != nil {
+			seconds = 600
+		}
+		time.Sleep(time.Duration(seconds) * time.Second)
+		hello(res, req)
+	case "502":
+		http.Error(res, fmt.Sprintf("bad gateway from instance %s", instance), http.StatusBadGateway)
+	case "exit":
+		os.Exit(1)
+	case "unlisten":
+		fmt.Fprintf(res, "instance %s stopped listening\n", instance)
+		close(unlistened)
+		server.SetKeepAlivesEnabled(false)
+		listener.Close()
+	default:
+		http.Error(res, fmt.Sprintf("unknown mode %q", mode), http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// receipt describes the body of an upload.
+type receipt struct {
+	Bytes         int64  `json:"bytes"`
+	Sha256        string `json:"sha256"`
+	ContentLength int64  `json:"content_length"`
+	Chunked       bool   `json:"chunked"`
+}
+
+func main() {
+	http.HandleFunc("/upload", upload)
+	fmt.Println("listening...")
+	err := http.ListenAndServe(":"+os.Getenv("PORT"), nil)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func upload(res http.ResponseWriter, req *http.Request) {
+	if req.Method != "POST" && req.Method != "PUT" {
+		http.Error(res, "uploads must be POST or PUT", http.StatusMethodNotAllowed)
+		return
+	}
+
+	hash := sha256.New()
+	bytes, err := io.Copy(hash, req.Body)
+	if err != nil {
+		fmt.Printf("upload failed after %d bytes: %s\n", bytes, err)
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	r := receipt{
+		Bytes:         bytes,
+		Sha256:        hex.EncodeToString(hash.Sum(nil)),
+		ContentLength: req.ContentLength,
+	}
+	for _, encoding := range req.TransferEncoding {
+		if encoding == "chunked" {
+			r.Chunked = true
+		}
+	}
+	fmt.Printf("received upload of %d bytes with sha256 %s\n", r.Bytes, r.Sha256)
+
+	res.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(res).Encode(r)
+}
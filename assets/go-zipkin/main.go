@@ -0,0 +1,37 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// b3 holds the Zipkin B3 headers of a request.
+type b3 struct {
+	TraceId      string `json:"trace_id"`
+	SpanId       string `json:"span_id"`
+	ParentSpanId string `json:"parent_span_id"`
+}
+
+func main() {
+	http.HandleFunc("/", trace)
+	fmt.Println("listening...")
+	err := http.ListenAndServe(":"+os.Getenv("PORT"), nil)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// trace logs the B3 headers of the request and responds with them as JSON.
+func trace(res http.ResponseWriter, req *http.Request) {
+	headers := b3{
+		TraceId:      req.Header.Get("X-B3-TraceId"),
+		SpanId:       req.Header.Get("X-B3-SpanId"),
+		ParentSpanId: req.Header.Get("X-B3-ParentSpanId"),
+	}
+	fmt.Printf("b3 trace_id:%q span_id:%q parent_span_id:%q\n", headers.TraceId, headers.SpanId, headers.ParentSpanId)
+
+	res.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(res).Encode(headers)
+}
@@ -1,14 +1,28 @@
 package main
 
 import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
 )
 
+const megabyte = 1024 * 1024
+
 func main() {
 	http.HandleFunc("/", hello)
 	http.HandleFunc("/requesturi/", echo)
+	http.HandleFunc("/headers", headers)
+	http.HandleFunc("/instance-identity", instanceIdentity)
+	http.HandleFunc("/disk/write/", writeDisk)
+	http.HandleFunc("/disk/exhaust", exhaustDisk)
 	fmt.Println("listening...")
 	err := http.ListenAndServe(":"+os.Getenv("PORT"), nil)
 	if err != nil {
@@ -23,3 +37,129 @@ func hello(res http.ResponseWriter, req *http.Request) {
 func echo(res http.ResponseWriter, req *http.Request) {
 	fmt.Fprintln(res, fmt.Sprintf("Request URI is [%s]\nQuery String is [%s]", req.RequestURI, req.URL.RawQuery))
 }
+
+// headers responds with the headers of the request as a JSON object, including
+// the Host header that net/http moves out of req.Header.
+func headers(res http.ResponseWriter, req *http.Request) {
+	echoed := http.Header{}
+	for name, values := range req.Header {
+		echoed[name] = values
+	}
+	echoed.Set("Host", req.Host)
+
+	res.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(res).Encode(echoed)
+}
+
+type identity struct {
+	CommonName          string    `json:"common_name"`
+	OrganizationalUnits []string  `json:"organizational_units"`
+	DNSNames            []string  `json:"dns_names"`
+	IPAddresses         []string  `json:"ip_addresses"`
+	NotBefore           time.Time `json:"not_before"`
+	NotAfter            time.Time `json:"not_after"`
+	Now                 time.Time `json:"now"`
+
+	InstanceGuid       string `json:"instance_guid"`
+	InstanceInternalIP string `json:"instance_internal_ip"`
+}
+
+// instanceIdentity parses the instance identity credentials in
+// CF_INSTANCE_CERT and CF_INSTANCE_KEY, and responds with the identity that
+// the certificate asserts, next to the instance's own view of it.
+func instanceIdentity(res http.ResponseWriter, req *http.Request) {
+	keyPair, err := tls.LoadX509KeyPair(os.Getenv("CF_INSTANCE_CERT"), os.Getenv("CF_INSTANCE_KEY"))
+	if err != nil {
+		http.Error(res, fmt.Sprintf("loading instance identity credentials: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	cert, err := x509.ParseCertificate(keyPair.Certificate[0])
+	if err != nil {
+		http.Error(res, fmt.Sprintf("parsing instance identity certificate: %s", err), http.StatusInternalServerError)
+		return
+	}
+
+	ipAddresses := []string{}
+	for _, ip := range cert.IPAddresses {
+		ipAddresses = append(ipAddresses, ip.String())
+	}
+
+	res.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(res).Encode(identity{
+		CommonName:          cert.Subject.CommonName,
+		OrganizationalUnits: cert.Subject.OrganizationalUnit,
+		DNSNames:            cert.DNSNames,
+		IPAddresses:         ipAddresses,
+		NotBefore:           cert.NotBefore,
+		NotAfter:            cert.NotAfter,
+		Now:                 time.Now(),
+		InstanceGuid:        os.Getenv("CF_INSTANCE_GUID"),
+		InstanceInternalIP:  os.Getenv("CF_INSTANCE_INTERNAL_IP"),
+	})
+}
+
+// writeDisk writes /disk/write/:mb megabytes to a file that is kept for the
+// lifetime of the instance.
+func writeDisk(res http.ResponseWriter, req *http.Request) {
+	mb, err := strconv.Atoi(strings.TrimPrefix(req.URL.Path, "/disk/write/"))
+	if err != nil {
+		http.Error(res, "size must be a number of megabytes", http.StatusBadRequest)
+		return
+	}
+
+	file, err := ioutil.TempFile("", "disk-write")
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	written, err := fill(file, mb)
+	if err != nil {
+		http.Error(res, fmt.Sprintf("Wrote %d MB before failing: %s", written, err.Error()), http.StatusInternalServerError)
+		return
+	}
+
+	fmt.Fprintf(res, "Wrote %d MB to %s\n", written, file.Name())
+}
+
+// exhaustDisk writes until a write fails and responds with the number of
+// megabytes written and whether the write failed because the disk is full.
+// The file is kept, so the disk stays full for the lifetime of the instance.
+func exhaustDisk(res http.ResponseWriter, req *http.Request) {
+	file, err := ioutil.TempFile("", "disk-exhaust")
+	if err != nil {
+		http.Error(res, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer file.Close()
+
+	written, err := fill(file, -1)
+	fmt.Printf("Wrote %d MB before failing: %s\n", written, err)
+	fmt.Fprintf(res, "Wrote %d MB before failing: %s\nDisk full: %t\n", written, err, isDiskFull(err))
+}
+
+// isDiskFull returns whether err is the failure of a write to a disk that is
+// out of quota or space.
+func isDiskFull(err error) bool {
+	if pathErr, ok := err.(*os.PathError); ok {
+		err = pathErr.Err
+	}
+	return err == syscall.EDQUOT || err == syscall.ENOSPC
+}
+
+// fill writes mb megabytes to file, or until a write fails when mb is
+// negative, and returns the number of megabytes written.
+func fill(file *os.File, mb int) (int, error) {
+	chunk := make([]byte, megabyte)
+	for written := 0; mb < 0 || written < mb; written++ {
+		if _, err := file.Write(chunk); err != nil {
+			return written, err
+		}
+		if err := file.Sync(); err != nil {
+			return written, err
+		}
+	}
+	return mb, nil
+}
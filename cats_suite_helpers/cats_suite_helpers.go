@@ -25,8 +25,24 @@ var (
 	TestSetup *workflowhelpers.ReproducibleTestSuiteSetup
 	ScpPath   string
 	SftpPath  string
+
+	suiteCleanups []func()
 )
 
+// AfterSuiteCleanup registers a cleanup to run on this node once the suite has
+// run, for resources that the specs of a Describe share.
+func AfterSuiteCleanup(cleanup func()) {
+	suiteCleanups = append(suiteCleanups, cleanup)
+}
+
+// RunSuiteCleanups runs the registered cleanups, last registered first.
+func RunSuiteCleanups() {
+	for i := len(suiteCleanups) - 1; i >= 0; i-- {
+		suiteCleanups[i]()
+	}
+	suiteCleanups = nil
+}
+
 func AppsDescribe(description string, callback func()) bool {
 	return Describe("[apps] "+description, func() {
 		BeforeEach(func() {
@@ -88,6 +104,29 @@ func DockerDescribe(description string, callback func()) bool {
 	})
 }
 
+func DomainsDescribe(description string, callback func()) bool {
+	return Describe("[domains] "+description, func() {
+		BeforeEach(func() {
+			if !Config.GetIncludeDomains() {
+				Skip(`Skipping this test because Config.IncludeDomains is set to 'false'.`)
+			}
+		})
+		callback()
+	})
+}
+
+func FeatureFlagsDescribe(description string, callback func()) bool {
+	return Describe("[feature_flags] "+description, func() {
+		BeforeEach(func() {
+			if !Config.GetIncludeFeatureFlags() {
+				Skip(`Skipping this test because Config.IncludeFeatureFlags is set to 'false'.
+			NOTE: These tests toggle platform-wide feature flags and may interfere with other tests running in parallel.`)
+			}
+		})
+		callback()
+	})
+}
+
 func TestCliVersionCheck(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "CliVersionCheck Suite")
@@ -105,6 +144,17 @@ NOTE: Ensure that your deployment has access to the internet before running this
 	})
 }
 
+func ManifestsDescribe(description string, callback func()) bool {
+	return Describe("[manifests] "+description, func() {
+		BeforeEach(func() {
+			if !Config.GetIncludeManifests() {
+				Skip(`Skipping this test because Config.IncludeManifests is set to 'false'.`)
+			}
+		})
+		callback()
+	})
+}
+
 func RouteServicesDescribe(description string, callback func()) bool {
 	return Describe("[route_services] "+description, func() {
 		BeforeEach(func() {
@@ -166,6 +216,21 @@ func ServicesDescribe(description string, callback func()) bool {
 	})
 }
 
+func ServiceInstanceSharingDescribe(description string, callback func()) bool {
+	return Describe("[service_instance_sharing] "+description, func() {
+		BeforeEach(func() {
+			if !Config.GetIncludeServices() {
+				Skip(`Skipping this test because Config.IncludeServices is set to 'false'.`)
+			}
+
+			if !Config.GetIncludeServiceInstanceSharing() {
+				Skip(`Skipping this test because Config.IncludeServiceInstanceSharing is set to 'false'.`)
+			}
+		})
+		callback()
+	})
+}
+
 func SshDescribe(description string, callback func()) bool {
 	return Describe("[ssh] "+description, func() {
 		BeforeEach(func() {
@@ -208,3 +273,29 @@ func GuidForAppName(appName string) string {
 	Expect(appGuid).NotTo(Equal(""))
 	return appGuid
 }
+
+func TcpRoutingDescribe(description string, callback func()) bool {
+	return Describe("[tcp_routing] "+description, func() {
+		BeforeEach(func() {
+			if !Config.GetIncludeTcpRouting() {
+				Skip(`Skipping this test because Config.IncludeTcpRouting is set to 'false'.`)
+			}
+
+			if Config.GetTcpDomain() == "" {
+				Skip(`Skipping this test because Config.TcpDomain is not set.`)
+			}
+		})
+		callback()
+	})
+}
+
+func UserProvidedServicesDescribe(description string, callback func()) bool {
+	return Describe("[user_provided_services] "+description, func() {
+		BeforeEach(func() {
+			if !Config.GetIncludeUserProvidedServices() {
+				Skip(`Skipping this test because Config.IncludeUserProvidedServices is set to 'false'.`)
+			}
+		})
+		callback()
+	})
+}
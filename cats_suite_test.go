@@ -13,14 +13,20 @@ import (
 	_ "github.com/cloudfoundry/cf-acceptance-tests/backend_compatibility"
 	_ "github.com/cloudfoundry/cf-acceptance-tests/detect"
 	_ "github.com/cloudfoundry/cf-acceptance-tests/docker"
+	_ "github.com/cloudfoundry/cf-acceptance-tests/domains"
+	_ "github.com/cloudfoundry/cf-acceptance-tests/feature_flags"
 	_ "github.com/cloudfoundry/cf-acceptance-tests/internet_dependent"
 	_ "github.com/cloudfoundry/cf-acceptance-tests/isolation_segments"
+	_ "github.com/cloudfoundry/cf-acceptance-tests/manifests"
 	_ "github.com/cloudfoundry/cf-acceptance-tests/route_services"
 	_ "github.com/cloudfoundry/cf-acceptance-tests/routing"
 	_ "github.com/cloudfoundry/cf-acceptance-tests/security_groups"
+	_ "github.com/cloudfoundry/cf-acceptance-tests/service_instance_sharing"
 	_ "github.com/cloudfoundry/cf-acceptance-tests/services"
 	_ "github.com/cloudfoundry/cf-acceptance-tests/ssh"
 	_ "github.com/cloudfoundry/cf-acceptance-tests/tasks"
+	_ "github.com/cloudfoundry/cf-acceptance-tests/tcp_routing"
+	_ "github.com/cloudfoundry/cf-acceptance-tests/user_provided_services"
 	_ "github.com/cloudfoundry/cf-acceptance-tests/v3"
 
 	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
@@ -83,6 +89,7 @@ func TestCATS(t *testing.T) {
 	})
 
 	AfterSuite(func() {
+		RunSuiteCleanups()
 		if TestSetup != nil {
 			TestSetup.Teardown()
 		}
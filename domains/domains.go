@@ -0,0 +1,251 @@
+package domains
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+
+	. "code.cloudfoundry.org/cf-routing-test-helpers/helpers"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/workflowhelpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/app_helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+const (
+	domainNameTakenCode = 130003
+	routeInvalidCode    = 210001
+	routeHostTakenCode  = 210003
+)
+
+// ccError is the body of a failed Cloud Controller v2 request.
+type ccError struct {
+	Code      int    `json:"code"`
+	ErrorCode string `json:"error_code"`
+}
+
+// ccPost posts the body to the v2 path and returns the error it failed with,
+// which is empty if the request succeeded.
+func ccPost(path string, body map[string]string) ccError {
+	data, err := json.Marshal(body)
+	Expect(err).NotTo(HaveOccurred())
+
+	response := cf.Cf("curl", path, "-X", "POST", "-d", string(data)).Wait(Config.DefaultTimeoutDuration())
+	Expect(response).To(Exit(0))
+
+	var failure ccError
+	Expect(json.Unmarshal(response.Out.Contents(), &failure)).To(Succeed())
+	return failure
+}
+
+func randomHost() string {
+	return strings.ToLower(random_name.CATSRandomName("ROUTE"))
+}
+
+func randomDomain() string {
+	return strings.ToLower(random_name.CATSRandomName("DOMAIN")) + "." + Config.GetAppsDomain()
+}
+
+func checkRoute(host, domain string) *Buffer {
+	checkRoute := cf.Cf("check-route", host, domain).Wait(Config.DefaultTimeoutDuration())
+	Expect(checkRoute).To(Exit(0))
+	return checkRoute.Out
+}
+
+var _ = DomainsDescribe("Domains and routes", func() {
+	var (
+		orgName   string
+		spaceName string
+	)
+
+	BeforeEach(func() {
+		orgName = TestSetup.RegularUserContext().Org
+		spaceName = TestSetup.RegularUserContext().Space
+	})
+
+	Describe("shared domains", func() {
+		var domainName string
+
+		BeforeEach(func() {
+			domainName = randomDomain()
+			workflowhelpers.AsUser(TestSetup.AdminUserContext(), Config.DefaultTimeoutDuration(), func() {
+				Expect(cf.Cf("create-shared-domain", domainName).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			})
+		})
+
+		AfterEach(func() {
+			workflowhelpers.AsUser(TestSetup.AdminUserContext(), Config.DefaultTimeoutDuration(), func() {
+				Expect(cf.Cf("delete-shared-domain", domainName, "-f").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			})
+		})
+
+		It("can be created by admin and used by every org", func() {
+			Expect(cf.Cf("domains").Wait(Config.DefaultTimeoutDuration())).To(Say(`%s\s+shared`, regexp.QuoteMeta(domainName)))
+
+			host := randomHost()
+			Expect(checkRoute(host, domainName)).To(Say("does not exist"))
+
+			Expect(cf.Cf("create-route", spaceName, domainName, "-n", host).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			Expect(checkRoute(host, domainName)).To(Say("does exist"))
+
+			Expect(cf.Cf("delete-route", domainName, "-n", host, "-f").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		})
+	})
+
+	Describe("private domains", func() {
+		var (
+			domainName     string
+			otherOrgName   string
+			otherOrgGuid   string
+			otherSpaceGuid string
+			hosts          []string
+		)
+
+		BeforeEach(func() {
+			domainName = randomDomain()
+			hosts = []string{}
+			otherOrgName = random_name.CATSRandomName("ORG")
+			otherSpaceName := random_name.CATSRandomName("SPACE")
+
+			workflowhelpers.AsUser(TestSetup.AdminUserContext(), Config.DefaultTimeoutDuration(), func() {
+				Expect(cf.Cf("create-domain", orgName, domainName).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+
+				Expect(cf.Cf("create-org", otherOrgName).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+				Expect(cf.Cf("create-space", otherSpaceName, "-o", otherOrgName).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+				otherOrgGuid = GetGuid(fmt.Sprintf("/v2/organizations?q=name:%s", otherOrgName), Config.DefaultTimeoutDuration())
+				otherSpaceGuid = GetGuid(fmt.Sprintf("/v2/spaces?q=name:%s&q=organization_guid:%s", otherSpaceName, otherOrgGuid), Config.DefaultTimeoutDuration())
+			})
+		})
+
+		AfterEach(func() {
+			for _, host := range hosts {
+				Expect(cf.Cf("delete-route", domainName, "-n", host, "-f").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			}
+
+			workflowhelpers.AsUser(TestSetup.AdminUserContext(), Config.DefaultTimeoutDuration(), func() {
+				Expect(cf.Cf("delete-org", otherOrgName, "-f").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+
+				Expect(cf.Cf("target", "-o", orgName).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+				Expect(cf.Cf("delete-domain", domainName, "-f").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			})
+		})
+
+		createRoute := func(host string) {
+			Expect(cf.Cf("create-route", spaceName, domainName, "-n", host).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			hosts = append(hosts, host)
+		}
+
+		// createRouteInOtherOrg creates a route on the private domain in the
+		// space of the other org.
+		createRouteInOtherOrg := func(host string) ccError {
+			var failure ccError
+			workflowhelpers.AsUser(TestSetup.AdminUserContext(), Config.DefaultTimeoutDuration(), func() {
+				failure = ccPost("/v2/routes", map[string]string{
+					"host":        host,
+					"domain_guid": GetGuid(fmt.Sprintf("/v2/private_domains?q=name:%s", domainName), Config.DefaultTimeoutDuration()),
+					"space_guid":  otherSpaceGuid,
+				})
+			})
+			return failure
+		}
+
+		It("is available to the owning org only", func() {
+			Expect(cf.Cf("domains").Wait(Config.DefaultTimeoutDuration())).To(Say(`%s\s+owned`, regexp.QuoteMeta(domainName)))
+
+			host := randomHost()
+			createRoute(host)
+			Expect(checkRoute(host, domainName)).To(Say("does exist"))
+
+			Expect(createRouteInOtherOrg(randomHost())).To(Equal(ccError{Code: routeInvalidCode, ErrorCode: "CF-RouteInvalid"}))
+		})
+
+		It("can be shared with another org", func() {
+			workflowhelpers.AsUser(TestSetup.AdminUserContext(), Config.DefaultTimeoutDuration(), func() {
+				Expect(cf.Cf("share-private-domain", otherOrgName, domainName).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			})
+
+			Expect(createRouteInOtherOrg(randomHost())).To(BeZero())
+		})
+
+		It("rejects a route whose host is already taken in another org", func() {
+			workflowhelpers.AsUser(TestSetup.AdminUserContext(), Config.DefaultTimeoutDuration(), func() {
+				Expect(cf.Cf("share-private-domain", otherOrgName, domainName).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			})
+
+			host := randomHost()
+			createRoute(host)
+
+			Expect(createRouteInOtherOrg(host)).To(Equal(ccError{Code: routeHostTakenCode, ErrorCode: "CF-RouteHostTaken"}))
+		})
+
+		It("rejects another domain with the same name", func() {
+			workflowhelpers.AsUser(TestSetup.AdminUserContext(), Config.DefaultTimeoutDuration(), func() {
+				taken := ccError{Code: domainNameTakenCode, ErrorCode: "CF-DomainNameTaken"}
+
+				Expect(ccPost("/v2/private_domains", map[string]string{
+					"name":                     domainName,
+					"owning_organization_guid": otherOrgGuid,
+				})).To(Equal(taken))
+				Expect(ccPost("/v2/shared_domains", map[string]string{
+					"name": domainName,
+				})).To(Equal(taken))
+			})
+		})
+	})
+
+	Describe("routes without apps", func() {
+		var host string
+
+		BeforeEach(func() {
+			host = randomHost()
+			Expect(cf.Cf("create-route", spaceName, Config.GetAppsDomain(), "-n", host).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		})
+
+		AfterEach(func() {
+			Expect(cf.Cf("delete-route", Config.GetAppsDomain(), "-n", host, "-f").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		})
+
+		It("reserves the route without routing traffic to it", func() {
+			Expect(checkRoute(host, Config.GetAppsDomain())).To(Say("does exist"))
+			Expect(cf.Cf("routes").Wait(Config.DefaultTimeoutDuration())).To(Say(`%s\s+%s\s*\n`, host, regexp.QuoteMeta(Config.GetAppsDomain())))
+
+			uri := Config.Protocol() + host + "." + Config.GetAppsDomain()
+			Eventually(func() string {
+				curl := helpers.Curl(Config, "-s", "-o", "/dev/null", "-w", "%{http_code}", uri).Wait(Config.DefaultTimeoutDuration())
+				return string(curl.Out.Contents())
+			}, Config.DefaultTimeoutDuration()).Should(Equal("404"))
+		})
+
+		It("keeps the route in the space until orphaned routes are deleted", func() {
+			appName := random_name.CATSRandomName("APP")
+			mappedHost := randomHost()
+			Expect(cf.Cf("push", appName,
+				"--no-start",
+				"-b", Config.GetRubyBuildpackName(),
+				"-m", DEFAULT_MEMORY_LIMIT,
+				"-p", assets.NewAssets().Dora,
+				"-d", Config.GetAppsDomain(),
+				"-n", mappedHost,
+			).Wait(Config.CfPushTimeoutDuration())).To(Exit(0))
+
+			defer func() {
+				app_helpers.AppReport(appName, Config.DefaultTimeoutDuration())
+				Expect(cf.Cf("delete", appName, "-f", "-r").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			}()
+
+			Expect(cf.Cf("delete-orphaned-routes", "-f").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+
+			Expect(checkRoute(host, Config.GetAppsDomain())).To(Say("does not exist"))
+			Expect(checkRoute(mappedHost, Config.GetAppsDomain())).To(Say("does exist"))
+		})
+	})
+})
@@ -0,0 +1,237 @@
+package feature_flags
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/workflowhelpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/app_helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/feature_flag_helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/services"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/skip_messages"
+)
+
+const featureDisabledError = "Feature Disabled"
+
+func combinedOutput(session *Session) string {
+	return string(session.Out.Contents()) + string(session.Err.Contents())
+}
+
+func pushDora(appName string) {
+	Expect(cf.Cf("push", appName, "--no-start", "-b", Config.GetRubyBuildpackName(), "-m", DEFAULT_MEMORY_LIMIT, "-p", assets.NewAssets().Dora, "-d", Config.GetAppsDomain()).Wait(Config.CfPushTimeoutDuration())).To(Exit(0))
+	app_helpers.SetBackend(appName)
+	Expect(cf.Cf("start", appName).Wait(Config.CfPushTimeoutDuration())).To(Exit(0))
+}
+
+var _ = FeatureFlagsDescribe("Feature Flags", func() {
+	var snapshot map[string]bool
+
+	BeforeEach(func() {
+		snapshot = FetchFeatureFlags()
+	})
+
+	AfterEach(func() {
+		RestoreFeatureFlags(snapshot)
+	})
+
+	Describe("app_bits_upload", func() {
+		var appName string
+
+		BeforeEach(func() {
+			appName = random_name.CATSRandomName("APP")
+		})
+
+		AfterEach(func() {
+			app_helpers.AppReport(appName, Config.DefaultTimeoutDuration())
+			Expect(cf.Cf("delete", appName, "-f", "-r").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		})
+
+		It("prevents non-admin users from uploading app bits while disabled", func() {
+			SetFeatureFlag("app_bits_upload", false)
+
+			push := cf.Cf("push", appName, "--no-start", "-b", Config.GetRubyBuildpackName(), "-m", DEFAULT_MEMORY_LIMIT, "-p", assets.NewAssets().Dora, "-d", Config.GetAppsDomain()).Wait(Config.CfPushTimeoutDuration())
+			Expect(push).To(Exit(1))
+			Expect(combinedOutput(push)).To(ContainSubstring(featureDisabledError))
+
+			SetFeatureFlag("app_bits_upload", true)
+
+			pushDora(appName)
+		})
+	})
+
+	Describe("task_creation", func() {
+		var appName string
+
+		BeforeEach(func() {
+			if !Config.GetIncludeTasks() {
+				Skip(skip_messages.SkipTasksMessage)
+			}
+
+			appName = random_name.CATSRandomName("APP")
+			pushDora(appName)
+		})
+
+		AfterEach(func() {
+			app_helpers.AppReport(appName, Config.DefaultTimeoutDuration())
+			Expect(cf.Cf("delete", appName, "-f", "-r").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		})
+
+		It("prevents tasks from being created while disabled", func() {
+			SetFeatureFlag("task_creation", false)
+
+			runTask := cf.Cf("run-task", appName, "ls", "--name", "feature-flag-task").Wait(Config.DefaultTimeoutDuration())
+			Expect(runTask).To(Exit(1))
+			Expect(combinedOutput(runTask)).To(ContainSubstring(featureDisabledError))
+
+			SetFeatureFlag("task_creation", true)
+
+			runTask = cf.Cf("run-task", appName, "ls", "--name", "feature-flag-task").Wait(Config.DefaultTimeoutDuration())
+			Expect(runTask).To(Exit(0))
+		})
+	})
+
+	Describe("diego_docker", func() {
+		var appName string
+
+		BeforeEach(func() {
+			if !Config.GetIncludeDocker() {
+				Skip(skip_messages.SkipDockerMessage)
+			}
+			if Config.GetBackend() != "diego" {
+				Skip(skip_messages.SkipDiegoMessage)
+			}
+
+			appName = random_name.CATSRandomName("APP")
+		})
+
+		AfterEach(func() {
+			app_helpers.AppReport(appName, Config.DefaultTimeoutDuration())
+			Expect(cf.Cf("delete", appName, "-f", "-r").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		})
+
+		It("prevents docker apps from being pushed while disabled", func() {
+			SetFeatureFlag("diego_docker", false)
+
+			push := cf.Cf("push", appName, "--no-start", "-o", "cloudfoundry/diego-docker-app:latest", "-m", DEFAULT_MEMORY_LIMIT, "-d", Config.GetAppsDomain()).Wait(Config.CfPushTimeoutDuration())
+			Expect(push).To(Exit(1))
+			Expect(combinedOutput(push)).To(ContainSubstring("Docker support has not been enabled"))
+
+			SetFeatureFlag("diego_docker", true)
+
+			push = cf.Cf("push", appName, "--no-start", "-o", "cloudfoundry/diego-docker-app:latest", "-m", DEFAULT_MEMORY_LIMIT, "-d", Config.GetAppsDomain()).Wait(Config.CfPushTimeoutDuration())
+			Expect(push).To(Exit(0))
+		})
+	})
+
+	Describe("user_org_creation", func() {
+		var orgName string
+
+		BeforeEach(func() {
+			orgName = random_name.CATSRandomName("ORG")
+		})
+
+		AfterEach(func() {
+			workflowhelpers.AsUser(TestSetup.AdminUserContext(), Config.DefaultTimeoutDuration(), func() {
+				Expect(cf.Cf("delete-org", orgName, "-f").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			})
+		})
+
+		It("only allows non-admin users to create orgs while enabled", func() {
+			SetFeatureFlag("user_org_creation", false)
+
+			createOrg := cf.Cf("create-org", orgName).Wait(Config.DefaultTimeoutDuration())
+			Expect(createOrg).To(Exit(1))
+			Expect(combinedOutput(createOrg)).To(ContainSubstring("You are not authorized to perform the requested action"))
+
+			SetFeatureFlag("user_org_creation", true)
+
+			createOrg = cf.Cf("create-org", orgName).Wait(Config.DefaultTimeoutDuration())
+			Expect(createOrg).To(Exit(0))
+		})
+	})
+
+	Describe("service_instance_sharing", func() {
+		var (
+			broker          services.ServiceBroker
+			instanceName    string
+			instanceGuid    string
+			targetSpaceName string
+			targetSpaceGuid string
+		)
+
+		shareServiceInstance := func() string {
+			sharePath := fmt.Sprintf("/v3/service_instances/%s/relationships/shared_spaces", instanceGuid)
+			shareBody := fmt.Sprintf(`{"data":[{"guid":"%s"}]}`, targetSpaceGuid)
+
+			var share *Session
+			workflowhelpers.AsUser(TestSetup.AdminUserContext(), Config.DefaultTimeoutDuration(), func() {
+				share = cf.Cf("curl", sharePath, "-X", "POST", "-d", shareBody).Wait(Config.DefaultTimeoutDuration())
+				Expect(share).To(Exit(0))
+			})
+			return string(share.Out.Contents())
+		}
+
+		BeforeEach(func() {
+			if !Config.GetIncludeServices() {
+				Skip(skip_messages.SkipServicesMessage)
+			}
+
+			broker = services.NewServiceBroker(
+				random_name.CATSRandomName("BRKR"),
+				assets.NewAssets().ServiceBroker,
+				TestSetup,
+			)
+			broker.Push(Config)
+			broker.Configure()
+			broker.Create()
+			broker.PublicizePlans()
+
+			instanceName = random_name.CATSRandomName("SVIN")
+			instanceGuid = broker.CreateServiceInstance(instanceName)
+
+			targetSpaceName = random_name.CATSRandomName("SPACE")
+			workflowhelpers.AsUser(TestSetup.AdminUserContext(), Config.DefaultTimeoutDuration(), func() {
+				Expect(cf.Cf("create-space", targetSpaceName, "-o", TestSetup.RegularUserContext().Org).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+				Expect(cf.Cf("target", "-o", TestSetup.RegularUserContext().Org).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+
+				spaceGuid := cf.Cf("space", targetSpaceName, "--guid").Wait(Config.DefaultTimeoutDuration())
+				Expect(spaceGuid).To(Exit(0))
+				targetSpaceGuid = strings.TrimSpace(string(spaceGuid.Out.Contents()))
+			})
+		})
+
+		AfterEach(func() {
+			app_helpers.AppReport(broker.Name, Config.DefaultTimeoutDuration())
+
+			workflowhelpers.AsUser(TestSetup.AdminUserContext(), Config.DefaultTimeoutDuration(), func() {
+				unsharePath := fmt.Sprintf("/v3/service_instances/%s/relationships/shared_spaces/%s", instanceGuid, targetSpaceGuid)
+				Expect(cf.Cf("curl", unsharePath, "-X", "DELETE").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+				Expect(cf.Cf("delete-space", targetSpaceName, "-o", TestSetup.RegularUserContext().Org, "-f").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			})
+
+			Expect(cf.Cf("delete-service", instanceName, "-f").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			broker.Destroy()
+		})
+
+		It("prevents service instances from being shared while disabled", func() {
+			SetFeatureFlag("service_instance_sharing", false)
+
+			Expect(shareServiceInstance()).To(ContainSubstring(featureDisabledError))
+
+			SetFeatureFlag("service_instance_sharing", true)
+
+			shareResponse := shareServiceInstance()
+			Expect(shareResponse).NotTo(ContainSubstring(featureDisabledError))
+			Expect(shareResponse).To(ContainSubstring(targetSpaceGuid))
+		})
+	})
+})
@@ -0,0 +1,46 @@
+package app_helpers
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+)
+
+var syslogDrainAddressPattern = regexp.MustCompile(`ADDRESS: \|(.*)\|`)
+
+// GetSyslogDrainAddress waits for the syslog drain listener app to log the
+// address it listens on and returns it.
+func GetSyslogDrainAddress(listenerAppName string) string {
+	var address string
+	Eventually(func() string {
+		logs := cf.Cf("logs", listenerAppName, "--recent").Wait(Config.DefaultTimeoutDuration())
+		Expect(logs).To(Exit(0))
+		if match := syslogDrainAddressPattern.FindSubmatch(logs.Out.Contents()); match != nil {
+			address = string(match[1])
+		}
+		return address
+	}, Config.DefaultTimeoutDuration()).ShouldNot(BeEmpty())
+
+	return address
+}
+
+// WriteLogsUntilInterrupted curls path on the app, which makes it log, every
+// few seconds until interrupt is closed. It is meant to run in a goroutine.
+func WriteLogsUntilInterrupted(interrupt chan struct{}, appName, path string) {
+	defer GinkgoRecover()
+	for {
+		select {
+		case <-interrupt:
+			return
+		default:
+			helpers.CurlAppWithTimeout(Config, appName, path, Config.DefaultTimeoutDuration())
+			time.Sleep(3 * time.Second)
+		}
+	}
+}
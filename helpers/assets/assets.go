@@ -7,6 +7,13 @@ type Assets struct {
 	DotnetCore               string
 	Fuse                     string
 	Golang                   string
+	GoServiceBroker          string
+	GoRouteService           string
+	GoStreaming              string
+	GoTcpEcho                string
+	GoUnreliable             string
+	GoUpload                 string
+	GoZipkin                 string
 	HelloWorld               string
 	HelloRouting             string
 	Java                     string
@@ -26,7 +33,6 @@ type Assets struct {
 	LoggingRouteService      string
 	WorkerApp                string
 	LatticeApp               string
-	SpringSleuthZip          string
 }
 
 func NewAssets() Assets {
@@ -37,6 +43,13 @@ func NewAssets() Assets {
 		DotnetCore:               "assets/dotnet-core",
 		Fuse:                     "assets/fuse-mount",
 		Golang:                   "assets/golang",
+		GoServiceBroker:          "assets/go-service-broker",
+		GoRouteService:           "assets/go-route-service",
+		GoStreaming:              "assets/go-streaming",
+		GoTcpEcho:                "assets/go-tcp-echo",
+		GoUnreliable:             "assets/go-unreliable",
+		GoUpload:                 "assets/go-upload",
+		GoZipkin:                 "assets/go-zipkin",
 		HelloRouting:             "assets/hello-routing",
 		HelloWorld:               "assets/hello-world",
 		Java:                     "assets/java",
@@ -56,6 +69,5 @@ func NewAssets() Assets {
 		LoggingRouteService:    "assets/logging-route-service",
 		WorkerApp:              "assets/worker-app",
 		LatticeApp:             "assets/lattice-app",
-		SpringSleuthZip:        "assets/spring-sleuth/spring-sleuth.jar",
 	}
 }
@@ -0,0 +1,72 @@
+package certificates
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"time"
+)
+
+// ServerChain returns the certificate chain that the TLS server at address
+// presents for serverName, without verifying it.
+func ServerChain(address, serverName string, timeout time.Duration) ([]*x509.Certificate, error) {
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{
+		ServerName:         serverName,
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return conn.ConnectionState().PeerCertificates, nil
+}
+
+// LoadCaBundle reads the PEM encoded CA certificates in the file at path.
+func LoadCaBundle(path string) (*x509.CertPool, error) {
+	bundle, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(bundle) {
+		return nil, fmt.Errorf("no certificates found in %s", path)
+	}
+	return roots, nil
+}
+
+// VerifyChain verifies that the first certificate of chain is valid for
+// serverName and chains to roots, using the rest of chain as intermediates.
+func VerifyChain(chain []*x509.Certificate, roots *x509.CertPool, serverName string) error {
+	if len(chain) == 0 {
+		return errors.New("the chain has no certificates")
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range chain[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := chain[0].Verify(x509.VerifyOptions{
+		DNSName:       serverName,
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	return err
+}
+
+// InstanceIdentityOrganizationalUnits returns the organizational units that
+// Diego puts in the subject of the instance identity certificate of an app
+// instance.
+func InstanceIdentityOrganizationalUnits(appGuid, spaceGuid, orgGuid string) []string {
+	return []string{
+		"organization:" + orgGuid,
+		"space:" + spaceGuid,
+		"app:" + appGuid,
+	}
+}
@@ -0,0 +1,181 @@
+package certificates_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"time"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/certificates"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	der  []byte
+	key  *ecdsa.PrivateKey
+}
+
+// generateCert creates a certificate signed by parent, or a self-signed CA
+// certificate if parent is nil.
+func generateCert(commonName string, parent *testCert, dnsNames ...string) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     dnsNames,
+	}
+
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	if len(dnsNames) == 0 {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage = x509.KeyUsageCertSign
+	} else {
+		template.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		template.KeyUsage = x509.KeyUsageDigitalSignature
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	Expect(err).NotTo(HaveOccurred())
+	cert, err := x509.ParseCertificate(der)
+	Expect(err).NotTo(HaveOccurred())
+
+	return &testCert{cert: cert, der: der, key: key}
+}
+
+func writeCaBundle(certs ...*testCert) string {
+	bundle, err := ioutil.TempFile("", "ca-bundle")
+	Expect(err).NotTo(HaveOccurred())
+	defer bundle.Close()
+
+	for _, cert := range certs {
+		Expect(pem.Encode(bundle, &pem.Block{Type: "CERTIFICATE", Bytes: cert.der})).To(Succeed())
+	}
+	return bundle.Name()
+}
+
+var _ = Describe("Certificates", func() {
+	var (
+		ca           *testCert
+		intermediate *testCert
+		leaf         *testCert
+		server       *httptest.Server
+		bundlePath   string
+	)
+
+	BeforeEach(func() {
+		ca = generateCert("cats-ca", nil)
+		intermediate = generateCert("cats-intermediate", ca)
+		leaf = generateCert("apps", intermediate, "*.apps.example.com")
+
+		server = httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+		server.TLS = &tls.Config{
+			Certificates: []tls.Certificate{{
+				Certificate: [][]byte{leaf.der, intermediate.der},
+				PrivateKey:  leaf.key,
+			}},
+		}
+		server.StartTLS()
+
+		bundlePath = writeCaBundle(ca)
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.Remove(bundlePath)
+	})
+
+	serverChain := func() []*x509.Certificate {
+		chain, err := ServerChain(server.Listener.Addr().String(), "my-app.apps.example.com", time.Second)
+		Expect(err).NotTo(HaveOccurred())
+		return chain
+	}
+
+	Describe("ServerChain", func() {
+		It("returns the certificates the server presents", func() {
+			chain := serverChain()
+
+			Expect(chain).To(HaveLen(2))
+			Expect(chain[0].Subject.CommonName).To(Equal("apps"))
+			Expect(chain[1].Subject.CommonName).To(Equal("cats-intermediate"))
+		})
+	})
+
+	Describe("LoadCaBundle", func() {
+		It("fails for a file without certificates", func() {
+			empty, err := ioutil.TempFile("", "ca-bundle")
+			Expect(err).NotTo(HaveOccurred())
+			empty.Close()
+			defer os.Remove(empty.Name())
+
+			_, err = LoadCaBundle(empty.Name())
+			Expect(err).To(MatchError(ContainSubstring("no certificates found")))
+		})
+	})
+
+	Describe("VerifyChain", func() {
+		It("accepts a chain to the CA bundle that is valid for the server name", func() {
+			roots, err := LoadCaBundle(bundlePath)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(VerifyChain(serverChain(), roots, "my-app.apps.example.com")).To(Succeed())
+		})
+
+		It("rejects a chain for another server name", func() {
+			roots, err := LoadCaBundle(bundlePath)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(VerifyChain(serverChain(), roots, "my-app.other.example.com")).To(HaveOccurred())
+		})
+
+		It("rejects a chain to another CA", func() {
+			otherBundlePath := writeCaBundle(generateCert("other-ca", nil))
+			defer os.Remove(otherBundlePath)
+
+			roots, err := LoadCaBundle(otherBundlePath)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(VerifyChain(serverChain(), roots, "my-app.apps.example.com")).To(HaveOccurred())
+		})
+
+		It("rejects a chain without its intermediate", func() {
+			roots, err := LoadCaBundle(bundlePath)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(VerifyChain(serverChain()[:1], roots, "my-app.apps.example.com")).To(HaveOccurred())
+		})
+
+		It("rejects an empty chain", func() {
+			Expect(VerifyChain(nil, x509.NewCertPool(), "my-app.apps.example.com")).To(MatchError("the chain has no certificates"))
+		})
+	})
+
+	Describe("InstanceIdentityOrganizationalUnits", func() {
+		It("names the org, space and app of the instance", func() {
+			Expect(InstanceIdentityOrganizationalUnits("app-guid", "space-guid", "org-guid")).To(ConsistOf(
+				"organization:org-guid",
+				"space:space-guid",
+				"app:app-guid",
+			))
+		})
+	})
+})
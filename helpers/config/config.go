@@ -10,7 +10,11 @@ type CatsConfig interface {
 	GetIncludeContainerNetworking() bool
 	GetIncludeDetect() bool
 	GetIncludeDocker() bool
+	GetIncludeDomains() bool
+	GetIncludeFeatureFlags() bool
+	GetIncludeInstanceIdentity() bool
 	GetIncludeInternetDependent() bool
+	GetIncludeManifests() bool
 	GetIncludePrivilegedContainerSupport() bool
 	GetIncludeRouteServices() bool
 	GetIncludeRouting() bool
@@ -18,8 +22,11 @@ type CatsConfig interface {
 	GetIncludeSSO() bool
 	GetIncludeSecurityGroups() bool
 	GetIncludeServices() bool
+	GetIncludeServiceInstanceSharing() bool
 	GetIncludeSsh() bool
 	GetIncludeTasks() bool
+	GetIncludeTcpRouting() bool
+	GetIncludeUserProvidedServices() bool
 	GetIncludeV3() bool
 	GetIncludeIsolationSegments() bool
 	GetShouldKeepUser() bool
@@ -30,6 +37,7 @@ type CatsConfig interface {
 	GetAdminUser() string
 	GetApiEndpoint() string
 	GetAppsDomain() string
+	GetAppsDomainCaBundle() string
 	GetArtifactsDirectory() string
 	GetBackend() string
 	GetBinaryBuildpackName() string
@@ -38,6 +46,7 @@ type CatsConfig interface {
 	GetExistingUserPassword() string
 	GetGoBuildpackName() string
 	GetIsolationSegmentName() string
+	GetTcpDomain() string
 	GetJavaBuildpackName() string
 	GetNamePrefix() string
 	GetNodejsBuildpackName() string
@@ -45,17 +54,23 @@ type CatsConfig interface {
 	GetPersistentAppOrg() string
 	GetPersistentAppQuotaName() string
 	GetPersistentAppSpace() string
+	GetRouterMaxHeaderBytes() int
 	GetRubyBuildpackName() string
 	Protocol() string
 
 	AsyncServiceOperationTimeoutDuration() time.Duration
+	BrokerClientTimeoutDuration() time.Duration
 	BrokerStartTimeoutDuration() time.Duration
 	CfPushTimeoutDuration() time.Duration
 	DefaultTimeoutDuration() time.Duration
 	DetectTimeoutDuration() time.Duration
 	GetScaledTimeout(time.Duration) time.Duration
+	IdleConnectionTimeoutDuration() time.Duration
 	LongCurlTimeoutDuration() time.Duration
 	LongTimeoutDuration() time.Duration
+	RouteServiceSignatureTimeoutDuration() time.Duration
+	RouterPruneTimeoutDuration() time.Duration
+	RouterRequestTimeoutDuration() time.Duration
 	SleepTimeoutDuration() time.Duration
 }
 
@@ -34,17 +34,28 @@ type config struct {
 
 	IsolationSegmentName *string `json:"isolation_segment_name"`
 
+	TcpDomain *string `json:"tcp_domain"`
+
+	AppsDomainCaBundle *string `json:"apps_domain_ca_bundle"`
+
+	RouterMaxHeaderBytes *int `json:"router_max_header_bytes"`
+
 	Backend           *string `json:"backend"`
 	SkipSSLValidation *bool   `json:"skip_ssl_validation"`
 
 	ArtifactsDirectory *string `json:"artifacts_directory"`
 
 	AsyncServiceOperationTimeout *int `json:"async_service_operation_timeout"`
+	BrokerClientTimeout          *int `json:"broker_client_timeout"`
 	BrokerStartTimeout           *int `json:"broker_start_timeout"`
 	CfPushTimeout                *int `json:"cf_push_timeout"`
 	DefaultTimeout               *int `json:"default_timeout"`
 	DetectTimeout                *int `json:"detect_timeout"`
+	IdleConnectionTimeout        *int `json:"idle_connection_timeout"`
 	LongCurlTimeout              *int `json:"long_curl_timeout"`
+	RouteServiceSignatureTimeout *int `json:"route_service_signature_timeout"`
+	RouterPruneTimeout           *int `json:"router_prune_timeout"`
+	RouterRequestTimeout         *int `json:"router_request_timeout"`
 	SleepTimeout                 *int `json:"sleep_timeout"`
 
 	TimeoutScale *float64 `json:"timeout_scale"`
@@ -63,15 +74,22 @@ type config struct {
 	IncludeContainerNetworking        *bool `json:"include_container_networking"`
 	IncludeDetect                     *bool `json:"include_detect"`
 	IncludeDocker                     *bool `json:"include_docker"`
+	IncludeDomains                    *bool `json:"include_domains"`
+	IncludeFeatureFlags               *bool `json:"include_feature_flags"`
+	IncludeInstanceIdentity           *bool `json:"include_instance_identity"`
 	IncludeInternetDependent          *bool `json:"include_internet_dependent"`
+	IncludeManifests                  *bool `json:"include_manifests"`
 	IncludePrivilegedContainerSupport *bool `json:"include_privileged_container_support"`
 	IncludeRouteServices              *bool `json:"include_route_services"`
 	IncludeRouting                    *bool `json:"include_routing"`
 	IncludeSSO                        *bool `json:"include_sso"`
 	IncludeSecurityGroups             *bool `json:"include_security_groups"`
 	IncludeServices                   *bool `json:"include_services"`
+	IncludeServiceInstanceSharing     *bool `json:"include_service_instance_sharing"`
 	IncludeSsh                        *bool `json:"include_ssh"`
 	IncludeTasks                      *bool `json:"include_tasks"`
+	IncludeTcpRouting                 *bool `json:"include_tcp_routing"`
+	IncludeUserProvidedServices       *bool `json:"include_user_provided_services"`
 	IncludeV3                         *bool `json:"include_v3"`
 	IncludeZipkin                     *bool `json:"include_zipkin"`
 	IncludeIsolationSegments          *bool `json:"include_isolation_segments"`
@@ -107,6 +125,11 @@ func getDefaults() config {
 
 	defaults.IsolationSegmentName = ptrToString("")
 
+	defaults.TcpDomain = ptrToString("")
+
+	defaults.AppsDomainCaBundle = ptrToString("")
+	defaults.RouterMaxHeaderBytes = ptrToInt(0)
+
 	defaults.BinaryBuildpackName = ptrToString("binary_buildpack")
 	defaults.GoBuildpackName = ptrToString("go_buildpack")
 	defaults.JavaBuildpackName = ptrToString("java_buildpack")
@@ -123,16 +146,23 @@ func getDefaults() config {
 	defaults.IncludeBackendCompatiblity = ptrToBool(false)
 	defaults.IncludeContainerNetworking = ptrToBool(false)
 	defaults.IncludeDocker = ptrToBool(false)
+	defaults.IncludeDomains = ptrToBool(false)
+	defaults.IncludeFeatureFlags = ptrToBool(false)
+	defaults.IncludeInstanceIdentity = ptrToBool(false)
 	defaults.IncludeInternetDependent = ptrToBool(false)
+	defaults.IncludeManifests = ptrToBool(false)
 	defaults.IncludeRouteServices = ptrToBool(false)
 	defaults.IncludeSecurityGroups = ptrToBool(false)
 	defaults.IncludeServices = ptrToBool(false)
+	defaults.IncludeServiceInstanceSharing = ptrToBool(false)
 	defaults.IncludeSsh = ptrToBool(false)
 	defaults.IncludeV3 = ptrToBool(false)
 	defaults.IncludePrivilegedContainerSupport = ptrToBool(false)
 	defaults.IncludeZipkin = ptrToBool(false)
 	defaults.IncludeSSO = ptrToBool(false)
 	defaults.IncludeTasks = ptrToBool(false)
+	defaults.IncludeTcpRouting = ptrToBool(false)
+	defaults.IncludeUserProvidedServices = ptrToBool(false)
 	defaults.IncludeIsolationSegments = ptrToBool(false)
 
 	defaults.UseHttp = ptrToBool(false)
@@ -140,11 +170,16 @@ func getDefaults() config {
 	defaults.ShouldKeepUser = ptrToBool(false)
 
 	defaults.AsyncServiceOperationTimeout = ptrToInt(2)
+	defaults.BrokerClientTimeout = ptrToInt(60)
 	defaults.BrokerStartTimeout = ptrToInt(5)
 	defaults.CfPushTimeout = ptrToInt(2)
 	defaults.DefaultTimeout = ptrToInt(30)
 	defaults.DetectTimeout = ptrToInt(5)
+	defaults.IdleConnectionTimeout = ptrToInt(60)
 	defaults.LongCurlTimeout = ptrToInt(2)
+	defaults.RouteServiceSignatureTimeout = ptrToInt(60)
+	defaults.RouterPruneTimeout = ptrToInt(120)
+	defaults.RouterRequestTimeout = ptrToInt(0)
 	defaults.SleepTimeout = ptrToInt(30)
 
 	defaults.ConfigurableTestPassword = ptrToString("")
@@ -223,6 +258,15 @@ func validateConfig(config *config) Errors {
 	if config.IsolationSegmentName == nil {
 		errs.Add(fmt.Errorf("* 'isolation_segment_name' must not be null"))
 	}
+	if config.TcpDomain == nil {
+		errs.Add(fmt.Errorf("* 'tcp_domain' must not be null"))
+	}
+	if config.AppsDomainCaBundle == nil {
+		errs.Add(fmt.Errorf("* 'apps_domain_ca_bundle' must not be null"))
+	}
+	if config.RouterMaxHeaderBytes == nil {
+		errs.Add(fmt.Errorf("* 'router_max_header_bytes' must not be null"))
+	}
 	if config.SkipSSLValidation == nil {
 		errs.Add(fmt.Errorf("* 'skip_ssl_validation' must not be null"))
 	}
@@ -232,6 +276,9 @@ func validateConfig(config *config) Errors {
 	if config.AsyncServiceOperationTimeout == nil {
 		errs.Add(fmt.Errorf("* 'async_service_operation_timeout' must not be null"))
 	}
+	if config.BrokerClientTimeout == nil {
+		errs.Add(fmt.Errorf("* 'broker_client_timeout' must not be null"))
+	}
 	if config.BrokerStartTimeout == nil {
 		errs.Add(fmt.Errorf("* 'broker_start_timeout' must not be null"))
 	}
@@ -244,9 +291,21 @@ func validateConfig(config *config) Errors {
 	if config.DetectTimeout == nil {
 		errs.Add(fmt.Errorf("* 'detect_timeout' must not be null"))
 	}
+	if config.IdleConnectionTimeout == nil {
+		errs.Add(fmt.Errorf("* 'idle_connection_timeout' must not be null"))
+	}
 	if config.LongCurlTimeout == nil {
 		errs.Add(fmt.Errorf("* 'long_curl_timeout' must not be null"))
 	}
+	if config.RouteServiceSignatureTimeout == nil {
+		errs.Add(fmt.Errorf("* 'route_service_signature_timeout' must not be null"))
+	}
+	if config.RouterPruneTimeout == nil {
+		errs.Add(fmt.Errorf("* 'router_prune_timeout' must not be null"))
+	}
+	if config.RouterRequestTimeout == nil {
+		errs.Add(fmt.Errorf("* 'router_request_timeout' must not be null"))
+	}
 	if config.SleepTimeout == nil {
 		errs.Add(fmt.Errorf("* 'sleep_timeout' must not be null"))
 	}
@@ -292,9 +351,21 @@ func validateConfig(config *config) Errors {
 	if config.IncludeDocker == nil {
 		errs.Add(fmt.Errorf("* 'include_docker' must not be null"))
 	}
+	if config.IncludeDomains == nil {
+		errs.Add(fmt.Errorf("* 'include_domains' must not be null"))
+	}
+	if config.IncludeFeatureFlags == nil {
+		errs.Add(fmt.Errorf("* 'include_feature_flags' must not be null"))
+	}
+	if config.IncludeInstanceIdentity == nil {
+		errs.Add(fmt.Errorf("* 'include_instance_identity' must not be null"))
+	}
 	if config.IncludeInternetDependent == nil {
 		errs.Add(fmt.Errorf("* 'include_internet_dependent' must not be null"))
 	}
+	if config.IncludeManifests == nil {
+		errs.Add(fmt.Errorf("* 'include_manifests' must not be null"))
+	}
 	if config.IncludePrivilegedContainerSupport == nil {
 		errs.Add(fmt.Errorf("* 'include_privileged_container_support' must not be null"))
 	}
@@ -313,12 +384,21 @@ func validateConfig(config *config) Errors {
 	if config.IncludeServices == nil {
 		errs.Add(fmt.Errorf("* 'include_services' must not be null"))
 	}
+	if config.IncludeServiceInstanceSharing == nil {
+		errs.Add(fmt.Errorf("* 'include_service_instance_sharing' must not be null"))
+	}
 	if config.IncludeSsh == nil {
 		errs.Add(fmt.Errorf("* 'include_ssh' must not be null"))
 	}
 	if config.IncludeTasks == nil {
 		errs.Add(fmt.Errorf("* 'include_tasks' must not be null"))
 	}
+	if config.IncludeTcpRouting == nil {
+		errs.Add(fmt.Errorf("* 'include_tcp_routing' must not be null"))
+	}
+	if config.IncludeUserProvidedServices == nil {
+		errs.Add(fmt.Errorf("* 'include_user_provided_services' must not be null"))
+	}
 	if config.IncludeV3 == nil {
 		errs.Add(fmt.Errorf("* 'include_v3' must not be null"))
 	}
@@ -469,6 +549,22 @@ func (c *config) LongCurlTimeoutDuration() time.Duration {
 	return time.Duration(*c.LongCurlTimeout) * time.Minute
 }
 
+func (c *config) IdleConnectionTimeoutDuration() time.Duration {
+	return time.Duration(*c.IdleConnectionTimeout) * time.Second
+}
+
+func (c *config) RouteServiceSignatureTimeoutDuration() time.Duration {
+	return time.Duration(*c.RouteServiceSignatureTimeout) * time.Second
+}
+
+func (c *config) RouterPruneTimeoutDuration() time.Duration {
+	return time.Duration(*c.RouterPruneTimeout) * time.Second
+}
+
+func (c *config) RouterRequestTimeoutDuration() time.Duration {
+	return time.Duration(*c.RouterRequestTimeout) * time.Second
+}
+
 func (c *config) SleepTimeoutDuration() time.Duration {
 	return time.Duration(*c.SleepTimeout) * time.Second
 }
@@ -481,6 +577,10 @@ func (c *config) CfPushTimeoutDuration() time.Duration {
 	return time.Duration(*c.CfPushTimeout) * time.Minute
 }
 
+func (c *config) BrokerClientTimeoutDuration() time.Duration {
+	return time.Duration(*c.BrokerClientTimeout) * time.Second
+}
+
 func (c *config) BrokerStartTimeoutDuration() time.Duration {
 	return time.Duration(*c.BrokerStartTimeout) * time.Minute
 }
@@ -523,6 +623,18 @@ func (c *config) GetIsolationSegmentName() string {
 	return *c.IsolationSegmentName
 }
 
+func (c *config) GetTcpDomain() string {
+	return *c.TcpDomain
+}
+
+func (c *config) GetAppsDomainCaBundle() string {
+	return *c.AppsDomainCaBundle
+}
+
+func (c *config) GetRouterMaxHeaderBytes() int {
+	return *c.RouterMaxHeaderBytes
+}
+
 func (c *config) GetNamePrefix() string {
 	return *c.NamePrefix
 }
@@ -583,10 +695,26 @@ func (c *config) GetIncludeDocker() bool {
 	return *c.IncludeDocker
 }
 
+func (c *config) GetIncludeDomains() bool {
+	return *c.IncludeDomains
+}
+
+func (c *config) GetIncludeFeatureFlags() bool {
+	return *c.IncludeFeatureFlags
+}
+
+func (c *config) GetIncludeInstanceIdentity() bool {
+	return *c.IncludeInstanceIdentity
+}
+
 func (c *config) GetIncludeInternetDependent() bool {
 	return *c.IncludeInternetDependent
 }
 
+func (c *config) GetIncludeManifests() bool {
+	return *c.IncludeManifests
+}
+
 func (c *config) GetIncludeRouteServices() bool {
 	return *c.IncludeRouteServices
 }
@@ -603,6 +731,14 @@ func (c *config) GetIncludeTasks() bool {
 	return *c.IncludeTasks
 }
 
+func (c *config) GetIncludeTcpRouting() bool {
+	return *c.IncludeTcpRouting
+}
+
+func (c *config) GetIncludeUserProvidedServices() bool {
+	return *c.IncludeUserProvidedServices
+}
+
 func (c *config) GetIncludePrivilegedContainerSupport() bool {
 	return *c.IncludePrivilegedContainerSupport
 }
@@ -615,6 +751,10 @@ func (c *config) GetIncludeServices() bool {
 	return *c.IncludeServices
 }
 
+func (c *config) GetIncludeServiceInstanceSharing() bool {
+	return *c.IncludeServiceInstanceSharing
+}
+
 func (c *config) GetIncludeSSO() bool {
 	return *c.IncludeSSO
 }
@@ -40,7 +40,12 @@ type testConfig struct {
 	BrokerStartTimeout           *int `json:"broker_start_timeout,omitempty"`
 	AsyncServiceOperationTimeout *int `json:"async_service_operation_timeout,omitempty"`
 	DetectTimeout                *int `json:"detect_timeout,omitempty"`
+	IdleConnectionTimeout        *int `json:"idle_connection_timeout,omitempty"`
 	SleepTimeout                 *int `json:"sleep_timeout,omitempty"`
+	RouterPruneTimeout           *int `json:"router_prune_timeout,omitempty"`
+	RouterRequestTimeout         *int `json:"router_request_timeout,omitempty"`
+	RouteServiceSignatureTimeout *int `json:"route_service_signature_timeout,omitempty"`
+	BrokerClientTimeout          *int `json:"broker_client_timeout,omitempty"`
 
 	// optional
 	Backend *string `json:"backend,omitempty"`
@@ -68,17 +73,28 @@ type allConfig struct {
 
 	IsolationSegmentName *string `json:"isolation_segment_name"`
 
+	TcpDomain *string `json:"tcp_domain"`
+
+	AppsDomainCaBundle *string `json:"apps_domain_ca_bundle"`
+
+	RouterMaxHeaderBytes *int `json:"router_max_header_bytes"`
+
 	Backend           *string `json:"backend"`
 	SkipSSLValidation *bool   `json:"skip_ssl_validation"`
 
 	ArtifactsDirectory *string `json:"artifacts_directory"`
 
 	AsyncServiceOperationTimeout *int `json:"async_service_operation_timeout"`
+	BrokerClientTimeout          *int `json:"broker_client_timeout"`
 	BrokerStartTimeout           *int `json:"broker_start_timeout"`
 	CfPushTimeout                *int `json:"cf_push_timeout"`
 	DefaultTimeout               *int `json:"default_timeout"`
 	DetectTimeout                *int `json:"detect_timeout"`
+	IdleConnectionTimeout        *int `json:"idle_connection_timeout"`
 	LongCurlTimeout              *int `json:"long_curl_timeout"`
+	RouteServiceSignatureTimeout *int `json:"route_service_signature_timeout"`
+	RouterPruneTimeout           *int `json:"router_prune_timeout"`
+	RouterRequestTimeout         *int `json:"router_request_timeout"`
 	SleepTimeout                 *int `json:"sleep_timeout"`
 
 	TimeoutScale *float64 `json:"timeout_scale"`
@@ -97,15 +113,22 @@ type allConfig struct {
 	IncludeContainerNetworking        *bool `json:"include_container_networking"`
 	IncludeDetect                     *bool `json:"include_detect"`
 	IncludeDocker                     *bool `json:"include_docker"`
+	IncludeDomains                    *bool `json:"include_domains"`
+	IncludeFeatureFlags               *bool `json:"include_feature_flags"`
+	IncludeInstanceIdentity           *bool `json:"include_instance_identity"`
 	IncludeInternetDependent          *bool `json:"include_internet_dependent"`
+	IncludeManifests                  *bool `json:"include_manifests"`
 	IncludePrivilegedContainerSupport *bool `json:"include_privileged_container_support"`
 	IncludeRouteServices              *bool `json:"include_route_services"`
 	IncludeRouting                    *bool `json:"include_routing"`
 	IncludeSSO                        *bool `json:"include_sso"`
 	IncludeSecurityGroups             *bool `json:"include_security_groups"`
 	IncludeServices                   *bool `json:"include_services"`
+	IncludeServiceInstanceSharing     *bool `json:"include_service_instance_sharing"`
 	IncludeSsh                        *bool `json:"include_ssh"`
 	IncludeTasks                      *bool `json:"include_tasks"`
+	IncludeTcpRouting                 *bool `json:"include_tcp_routing"`
+	IncludeUserProvidedServices       *bool `json:"include_user_provided_services"`
 	IncludeV3                         *bool `json:"include_v3"`
 	IncludeZipkin                     *bool `json:"include_zipkin"`
 	IncludeIsolationSegments          *bool `json:"include_isolation_segments"`
@@ -185,6 +208,9 @@ var _ = Describe("Config", func() {
 		Expect(config.GetPersistentAppSpace()).To(Equal("CATS-persistent-space"))
 
 		Expect(config.GetIsolationSegmentName()).To(Equal(""))
+		Expect(config.GetTcpDomain()).To(Equal(""))
+		Expect(config.GetAppsDomainCaBundle()).To(Equal(""))
+		Expect(config.GetRouterMaxHeaderBytes()).To(BeZero())
 
 		Expect(config.GetIncludeApps()).To(BeTrue())
 		Expect(config.GetIncludeDetect()).To(BeTrue())
@@ -192,11 +218,16 @@ var _ = Describe("Config", func() {
 
 		Expect(config.GetIncludeBackendCompatiblity()).To(BeFalse())
 		Expect(config.GetIncludeDocker()).To(BeFalse())
+		Expect(config.GetIncludeDomains()).To(BeFalse())
+		Expect(config.GetIncludeFeatureFlags()).To(BeFalse())
+		Expect(config.GetIncludeInstanceIdentity()).To(BeFalse())
 		Expect(config.GetIncludeInternetDependent()).To(BeFalse())
+		Expect(config.GetIncludeManifests()).To(BeFalse())
 		Expect(config.GetIncludeRouteServices()).To(BeFalse())
 		Expect(config.GetIncludeContainerNetworking()).To(BeFalse())
 		Expect(config.GetIncludeSecurityGroups()).To(BeFalse())
 		Expect(config.GetIncludeServices()).To(BeFalse())
+		Expect(config.GetIncludeServiceInstanceSharing()).To(BeFalse())
 		Expect(config.GetIncludeSsh()).To(BeFalse())
 		Expect(config.GetIncludeV3()).To(BeFalse())
 		Expect(config.GetIncludeIsolationSegments()).To(BeFalse())
@@ -204,6 +235,8 @@ var _ = Describe("Config", func() {
 		Expect(config.GetIncludeZipkin()).To(BeFalse())
 		Expect(config.GetIncludeSSO()).To(BeFalse())
 		Expect(config.GetIncludeTasks()).To(BeFalse())
+		Expect(config.GetIncludeTcpRouting()).To(BeFalse())
+		Expect(config.GetIncludeUserProvidedServices()).To(BeFalse())
 
 		Expect(config.GetBackend()).To(Equal(""))
 
@@ -216,6 +249,11 @@ var _ = Describe("Config", func() {
 		Expect(config.CfPushTimeoutDuration()).To(Equal(2 * time.Minute))
 		Expect(config.DefaultTimeoutDuration()).To(Equal(30 * time.Second))
 		Expect(config.LongCurlTimeoutDuration()).To(Equal(2 * time.Minute))
+		Expect(config.IdleConnectionTimeoutDuration()).To(Equal(60 * time.Second))
+		Expect(config.RouterPruneTimeoutDuration()).To(Equal(120 * time.Second))
+		Expect(config.RouterRequestTimeoutDuration()).To(BeZero())
+		Expect(config.RouteServiceSignatureTimeoutDuration()).To(Equal(60 * time.Second))
+		Expect(config.BrokerClientTimeoutDuration()).To(Equal(60 * time.Second))
 
 		Expect(config.GetScaledTimeout(1)).To(Equal(time.Duration(1)))
 
@@ -255,6 +293,9 @@ var _ = Describe("Config", func() {
 			Expect(err.Error()).To(ContainSubstring("'persistent_app_space' must not be null"))
 
 			Expect(err.Error()).To(ContainSubstring("'isolation_segment_name' must not be null"))
+			Expect(err.Error()).To(ContainSubstring("'tcp_domain' must not be null"))
+			Expect(err.Error()).To(ContainSubstring("'apps_domain_ca_bundle' must not be null"))
+			Expect(err.Error()).To(ContainSubstring("'router_max_header_bytes' must not be null"))
 
 			Expect(err.Error()).To(ContainSubstring("'backend' must not be null"))
 			Expect(err.Error()).To(ContainSubstring("'skip_ssl_validation' must not be null"))
@@ -262,11 +303,16 @@ var _ = Describe("Config", func() {
 			Expect(err.Error()).To(ContainSubstring("'artifacts_directory' must not be null"))
 
 			Expect(err.Error()).To(ContainSubstring("'async_service_operation_timeout' must not be null"))
+			Expect(err.Error()).To(ContainSubstring("'broker_client_timeout' must not be null"))
 			Expect(err.Error()).To(ContainSubstring("'broker_start_timeout' must not be null"))
 			Expect(err.Error()).To(ContainSubstring("'cf_push_timeout' must not be null"))
 			Expect(err.Error()).To(ContainSubstring("'default_timeout' must not be null"))
 			Expect(err.Error()).To(ContainSubstring("'detect_timeout' must not be null"))
+			Expect(err.Error()).To(ContainSubstring("'idle_connection_timeout' must not be null"))
 			Expect(err.Error()).To(ContainSubstring("'long_curl_timeout' must not be null"))
+			Expect(err.Error()).To(ContainSubstring("'route_service_signature_timeout' must not be null"))
+			Expect(err.Error()).To(ContainSubstring("'router_prune_timeout' must not be null"))
+			Expect(err.Error()).To(ContainSubstring("'router_request_timeout' must not be null"))
 			Expect(err.Error()).To(ContainSubstring("'sleep_timeout' must not be null"))
 
 			Expect(err.Error()).To(ContainSubstring("'timeout_scale' must not be null"))
@@ -285,7 +331,11 @@ var _ = Describe("Config", func() {
 			Expect(err.Error()).To(ContainSubstring("'backend' must not be null"))
 			Expect(err.Error()).To(ContainSubstring("'include_detect' must not be null"))
 			Expect(err.Error()).To(ContainSubstring("'include_docker' must not be null"))
+			Expect(err.Error()).To(ContainSubstring("'include_domains' must not be null"))
+			Expect(err.Error()).To(ContainSubstring("'include_feature_flags' must not be null"))
+			Expect(err.Error()).To(ContainSubstring("'include_instance_identity' must not be null"))
 			Expect(err.Error()).To(ContainSubstring("'include_internet_dependent' must not be null"))
+			Expect(err.Error()).To(ContainSubstring("'include_manifests' must not be null"))
 			Expect(err.Error()).To(ContainSubstring("'include_privileged_container_support' must not be null"))
 			Expect(err.Error()).To(ContainSubstring("'include_route_services' must not be null"))
 			Expect(err.Error()).To(ContainSubstring("'include_routing' must not be null"))
@@ -293,8 +343,11 @@ var _ = Describe("Config", func() {
 			Expect(err.Error()).To(ContainSubstring("'include_sso' must not be null"))
 			Expect(err.Error()).To(ContainSubstring("'include_security_groups' must not be null"))
 			Expect(err.Error()).To(ContainSubstring("'include_services' must not be null"))
+			Expect(err.Error()).To(ContainSubstring("'include_service_instance_sharing' must not be null"))
 			Expect(err.Error()).To(ContainSubstring("'include_ssh' must not be null"))
 			Expect(err.Error()).To(ContainSubstring("'include_tasks' must not be null"))
+			Expect(err.Error()).To(ContainSubstring("'include_tcp_routing' must not be null"))
+			Expect(err.Error()).To(ContainSubstring("'include_user_provided_services' must not be null"))
 			Expect(err.Error()).To(ContainSubstring("'include_v3' must not be null"))
 			Expect(err.Error()).To(ContainSubstring("'include_zipkin' must not be null"))
 			Expect(err.Error()).To(ContainSubstring("'include_isolation_segments' must not be null"))
@@ -312,6 +365,11 @@ var _ = Describe("Config", func() {
 			testCfg.AsyncServiceOperationTimeout = ptrToInt(90)
 			testCfg.DetectTimeout = ptrToInt(100)
 			testCfg.SleepTimeout = ptrToInt(101)
+			testCfg.IdleConnectionTimeout = ptrToInt(102)
+			testCfg.RouterPruneTimeout = ptrToInt(103)
+			testCfg.RouterRequestTimeout = ptrToInt(104)
+			testCfg.BrokerClientTimeout = ptrToInt(105)
+			testCfg.RouteServiceSignatureTimeout = ptrToInt(106)
 		})
 
 		It("respects the overriden values", func() {
@@ -325,6 +383,11 @@ var _ = Describe("Config", func() {
 			Expect(config.AsyncServiceOperationTimeoutDuration()).To(Equal(90 * time.Minute))
 			Expect(config.DetectTimeoutDuration()).To(Equal(100 * time.Minute))
 			Expect(config.SleepTimeoutDuration()).To(Equal(101 * time.Second))
+			Expect(config.IdleConnectionTimeoutDuration()).To(Equal(102 * time.Second))
+			Expect(config.RouterPruneTimeoutDuration()).To(Equal(103 * time.Second))
+			Expect(config.RouterRequestTimeoutDuration()).To(Equal(104 * time.Second))
+			Expect(config.BrokerClientTimeoutDuration()).To(Equal(105 * time.Second))
+			Expect(config.RouteServiceSignatureTimeoutDuration()).To(Equal(106 * time.Second))
 		})
 	})
 
@@ -0,0 +1,151 @@
+package env_schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/validationerrors"
+)
+
+// Schema is the subset of JSON Schema needed to describe the environment
+// Cloud Foundry hands to an app: types, required keys, nested properties,
+// string patterns, enumerated values and numeric minimums.
+type Schema struct {
+	Type                 string
+	Properties           map[string]*Schema
+	Required             []string
+	Items                *Schema
+	AdditionalProperties *Schema
+	Pattern              string
+	Enum                 []interface{}
+	Minimum              *float64
+}
+
+// ValidateJSON decodes raw and validates it against schema. The returned
+// error lists every mismatch, one per line, prefixed with the JSON path
+// rooted at name.
+func ValidateJSON(name string, schema *Schema, raw string) error {
+	var value interface{}
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		return fmt.Errorf("%s: invalid JSON: %s", name, err.Error())
+	}
+	return Validate(name, schema, value)
+}
+
+// Validate checks an already-decoded JSON value against schema.
+func Validate(name string, schema *Schema, value interface{}) error {
+	errs := validationerrors.Errors{}
+	validate(name, schema, value, &errs)
+	if errs.Empty() {
+		return nil
+	}
+	return errs
+}
+
+func validate(path string, schema *Schema, value interface{}, errs *validationerrors.Errors) {
+	if schema == nil {
+		return
+	}
+
+	if schema.Type != "" && typeOf(value) != schema.Type {
+		if !(schema.Type == "number" && typeOf(value) == "integer") {
+			errs.Add(fmt.Errorf("%s: expected %s, got %s %s", path, schema.Type, typeOf(value), describe(value)))
+			return
+		}
+	}
+
+	if len(schema.Enum) > 0 && !inEnum(value, schema.Enum) {
+		errs.Add(fmt.Errorf("%s: expected one of %s, got %s", path, describe(schema.Enum), describe(value)))
+	}
+
+	switch v := value.(type) {
+	case string:
+		if schema.Pattern != "" && !regexp.MustCompile(schema.Pattern).MatchString(v) {
+			errs.Add(fmt.Errorf("%s: %s does not match pattern %q", path, describe(v), schema.Pattern))
+		}
+	case float64:
+		if schema.Minimum != nil && v < *schema.Minimum {
+			errs.Add(fmt.Errorf("%s: %v is less than minimum %v", path, v, *schema.Minimum))
+		}
+	case []interface{}:
+		for i, item := range v {
+			validate(fmt.Sprintf("%s[%d]", path, i), schema.Items, item, errs)
+		}
+	case map[string]interface{}:
+		for _, key := range schema.Required {
+			if _, ok := v[key]; !ok {
+				errs.Add(fmt.Errorf("%s.%s: required key is missing", path, key))
+			}
+		}
+
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if propertySchema, ok := schema.Properties[key]; ok {
+				validate(path+"."+key, propertySchema, v[key], errs)
+			} else {
+				validate(path+"."+key, schema.AdditionalProperties, v[key], errs)
+			}
+		}
+	}
+}
+
+func typeOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func inEnum(value interface{}, enum []interface{}) bool {
+	for _, candidate := range enum {
+		if reflect.DeepEqual(value, normalize(candidate)) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize round-trips a Go value through encoding/json so that enum
+// entries such as ints or []string compare equal to decoded JSON values.
+func normalize(value interface{}) interface{} {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return value
+	}
+	return decoded
+}
+
+func describe(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%#v", value)
+	}
+	return string(encoded)
+}
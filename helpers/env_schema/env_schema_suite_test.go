@@ -0,0 +1,13 @@
+package env_schema_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestEnvSchema(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "EnvSchema Suite")
+}
@@ -0,0 +1,152 @@
+package env_schema_test
+
+import (
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/env_schema"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+const vcapApplication = `{
+	"application_id": "4d5e6f70-8192-a3b4-c5d6-e7f809a1b2c3",
+	"application_name": "my-app",
+	"application_uris": ["my-app.bosh-lite.com"],
+	"application_version": "0a1b2c3d-4e5f-6a7b-8c9d-0e1f2a3b4c5d",
+	"limits": {"mem": 256, "disk": 1024, "fds": 16384},
+	"name": "my-app",
+	"space_id": "1a2b3c4d-5e6f-7a8b-9c0d-1e2f3a4b5c6d",
+	"space_name": "my-space",
+	"uris": ["my-app.bosh-lite.com"],
+	"version": "0a1b2c3d-4e5f-6a7b-8c9d-0e1f2a3b4c5d",
+	"instance_id": "a1b2c3d4-e5f6-4a7b-8c9d-0e1f",
+	"instance_index": 0,
+	"host": "0.0.0.0",
+	"port": 8080
+}`
+
+var _ = Describe("EnvSchema", func() {
+	Describe("ValidateJSON", func() {
+		It("reports invalid JSON", func() {
+			err := ValidateJSON("VCAP_APPLICATION", &Schema{Type: "object"}, "{not json")
+			Expect(err).To(MatchError(ContainSubstring("VCAP_APPLICATION: invalid JSON")))
+		})
+	})
+
+	Describe("Validate", func() {
+		It("distinguishes integers from numbers", func() {
+			Expect(ValidateJSON("x", &Schema{Type: "integer"}, "3")).To(Succeed())
+			Expect(ValidateJSON("x", &Schema{Type: "number"}, "3")).To(Succeed())
+			Expect(ValidateJSON("x", &Schema{Type: "integer"}, "3.5")).To(MatchError("x: expected integer, got number 3.5"))
+		})
+
+		It("reports every mismatch with its path", func() {
+			schema := &Schema{
+				Type:     "object",
+				Required: []string{"a", "b"},
+				Properties: map[string]*Schema{
+					"a": {Type: "array", Items: &Schema{Type: "string", Pattern: `^[a-z]+$`}},
+				},
+				AdditionalProperties: &Schema{Type: "boolean"},
+			}
+
+			err := ValidateJSON("root", schema, `{"a": ["ok", "NOT", 1], "c": "yes"}`)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal(`root.b: required key is missing
+root.a[1]: "NOT" does not match pattern "^[a-z]+$"
+root.a[2]: expected string, got integer 1
+root.c: expected boolean, got string "yes"`))
+		})
+
+		It("compares enum values after JSON normalization", func() {
+			schema := &Schema{Enum: []interface{}{[]string{"a", "b"}}}
+			Expect(ValidateJSON("x", schema, `["a", "b"]`)).To(Succeed())
+			Expect(ValidateJSON("x", schema, `["b", "a"]`)).To(MatchError(`x: expected one of [["a","b"]], got ["b","a"]`))
+		})
+
+		It("enforces minimums", func() {
+			min := 1.0
+			Expect(ValidateJSON("x", &Schema{Type: "integer", Minimum: &min}, "0")).To(MatchError("x: 0 is less than minimum 1"))
+		})
+	})
+
+	Describe("VcapApplicationSchema", func() {
+		It("accepts a well-formed VCAP_APPLICATION", func() {
+			schema := VcapApplicationSchema(ExpectedApplication{
+				Name:      "my-app",
+				SpaceName: "my-space",
+				URIs:      []string{"my-app.bosh-lite.com"},
+				MemoryMB:  256,
+				Instance:  true,
+			})
+			Expect(ValidateJSON("VCAP_APPLICATION", schema, vcapApplication)).To(Succeed())
+		})
+
+		It("reports values that differ from the expected application", func() {
+			schema := VcapApplicationSchema(ExpectedApplication{
+				Name:      "other-app",
+				SpaceName: "my-space",
+				MemoryMB:  512,
+			})
+
+			err := ValidateJSON("VCAP_APPLICATION", schema, vcapApplication)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`VCAP_APPLICATION.application_name: expected one of ["other-app"], got "my-app"`))
+			Expect(err.Error()).To(ContainSubstring(`VCAP_APPLICATION.limits.mem: expected one of [512], got 256`))
+			Expect(err.Error()).To(ContainSubstring(`VCAP_APPLICATION.name: expected one of ["other-app"], got "my-app"`))
+		})
+
+		It("only requires per-instance fields for long-running instances", func() {
+			task := `{
+				"application_id": "4d5e6f70-8192-a3b4-c5d6-e7f809a1b2c3",
+				"application_name": "my-app",
+				"application_uris": [],
+				"application_version": "0a1b2c3d-4e5f-6a7b-8c9d-0e1f2a3b4c5d",
+				"limits": {"mem": 256, "disk": 1024, "fds": 16384},
+				"name": "my-app",
+				"space_id": "1a2b3c4d-5e6f-7a8b-9c0d-1e2f3a4b5c6d",
+				"space_name": "my-space",
+				"uris": [],
+				"version": "0a1b2c3d-4e5f-6a7b-8c9d-0e1f2a3b4c5d"
+			}`
+			Expect(ValidateJSON("VCAP_APPLICATION", VcapApplicationSchema(ExpectedApplication{}), task)).To(Succeed())
+			Expect(ValidateJSON("VCAP_APPLICATION", VcapApplicationSchema(ExpectedApplication{Instance: true}), task)).To(MatchError(ContainSubstring("VCAP_APPLICATION.instance_id: required key is missing")))
+		})
+	})
+
+	Describe("VcapServicesSchema", func() {
+		It("accepts an empty object", func() {
+			Expect(ValidateJSON("VCAP_SERVICES", VcapServicesSchema(), "{}")).To(Succeed())
+		})
+
+		It("validates every bound instance", func() {
+			err := ValidateJSON("VCAP_SERVICES", VcapServicesSchema(), `{
+				"fake-service": [
+					{"name": "si", "label": "fake-service", "plan": "fake-plan", "tags": ["sql"], "credentials": {"user": "u"}},
+					{"name": "si2", "label": "fake-service", "plan": "fake-plan", "tags": [], "credentials": "nope"}
+				]
+			}`)
+			Expect(err).To(MatchError(`VCAP_SERVICES.fake-service[1].credentials: expected object, got string "nope"`))
+		})
+	})
+
+	Describe("InstanceEnvSchema", func() {
+		It("validates CF_INSTANCE_* and PORT values", func() {
+			env := map[string]interface{}{
+				"CF_INSTANCE_ADDR":        "10.0.0.1:61001",
+				"CF_INSTANCE_GUID":        "1f2e3d4c-5b6a-7980-a1b2-c3d4",
+				"CF_INSTANCE_INDEX":       "0",
+				"CF_INSTANCE_INTERNAL_IP": "10.255.0.2",
+				"CF_INSTANCE_IP":          "10.0.0.1",
+				"CF_INSTANCE_PORT":        "61001",
+				"CF_INSTANCE_PORTS":       `[{"external":61001,"internal":8080}]`,
+				"PORT":                    "eight",
+			}
+			Expect(Validate("env", InstanceEnvSchema(), env)).To(MatchError(`env.PORT: "eight" does not match pattern "^[0-9]+$"`))
+		})
+
+		It("validates the decoded CF_INSTANCE_PORTS", func() {
+			Expect(ValidateJSON("CF_INSTANCE_PORTS", InstancePortsSchema(), `[{"external":61001,"internal":8080}]`)).To(Succeed())
+			Expect(ValidateJSON("CF_INSTANCE_PORTS", InstancePortsSchema(), `[{"external":61001}]`)).To(MatchError("CF_INSTANCE_PORTS[0].internal: required key is missing"))
+		})
+	})
+})
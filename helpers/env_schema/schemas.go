@@ -0,0 +1,165 @@
+package env_schema
+
+const (
+	guidPattern     = `^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`
+	ipPattern       = `^[0-9]+\.[0-9]+\.[0-9]+\.[0-9]+$`
+	portPattern     = `^[0-9]+$`
+	addressPattern  = `^[0-9]+\.[0-9]+\.[0-9]+\.[0-9]+:[0-9]+$`
+	nonEmptyPattern = `.+`
+)
+
+// ExpectedApplication pins values in VCAP_APPLICATION that a spec knows in
+// advance. Zero values leave the corresponding field checked for shape only.
+type ExpectedApplication struct {
+	Name      string
+	SpaceName string
+	URIs      []string
+	MemoryMB  int
+	DiskMB    int
+
+	// Instance requires the per-instance fields Diego adds to long-running
+	// processes; leave it false when validating the environment of a task.
+	Instance bool
+}
+
+func VcapApplicationSchema(expected ExpectedApplication) *Schema {
+	limits := &Schema{
+		Type:     "object",
+		Required: []string{"mem", "disk", "fds"},
+		Properties: map[string]*Schema{
+			"mem":  {Type: "integer", Minimum: minimum(1)},
+			"disk": {Type: "integer", Minimum: minimum(1)},
+			"fds":  {Type: "integer", Minimum: minimum(1)},
+		},
+	}
+	if expected.MemoryMB != 0 {
+		limits.Properties["mem"].Enum = []interface{}{expected.MemoryMB}
+	}
+	if expected.DiskMB != 0 {
+		limits.Properties["disk"].Enum = []interface{}{expected.DiskMB}
+	}
+
+	uris := &Schema{Type: "array", Items: &Schema{Type: "string", Pattern: nonEmptyPattern}}
+	if expected.URIs != nil {
+		uris.Enum = []interface{}{expected.URIs}
+	}
+
+	schema := &Schema{
+		Type: "object",
+		Required: []string{
+			"application_id",
+			"application_name",
+			"application_uris",
+			"application_version",
+			"limits",
+			"name",
+			"space_id",
+			"space_name",
+			"uris",
+			"version",
+		},
+		Properties: map[string]*Schema{
+			"application_id":      {Type: "string", Pattern: guidPattern},
+			"application_name":    stringMatching(expected.Name),
+			"application_uris":    uris,
+			"application_version": {Type: "string", Pattern: guidPattern},
+			"limits":              limits,
+			"name":                stringMatching(expected.Name),
+			"space_id":            {Type: "string", Pattern: guidPattern},
+			"space_name":          stringMatching(expected.SpaceName),
+			"uris":                uris,
+			"version":             {Type: "string", Pattern: guidPattern},
+			"instance_id":         {Type: "string", Pattern: nonEmptyPattern},
+			"instance_index":      {Type: "integer", Minimum: minimum(0)},
+			"host":                {Type: "string", Pattern: nonEmptyPattern},
+			"port":                {Type: "integer", Minimum: minimum(1)},
+		},
+	}
+
+	if expected.Instance {
+		schema.Required = append(schema.Required, "instance_id", "instance_index", "host", "port")
+	}
+
+	return schema
+}
+
+// VcapServicesSchema describes VCAP_SERVICES: an object keyed by service
+// label whose values are the bound instances of that service. User-provided
+// instances carry no plan, so it is only checked when present.
+func VcapServicesSchema() *Schema {
+	return &Schema{
+		Type: "object",
+		AdditionalProperties: &Schema{
+			Type: "array",
+			Items: &Schema{
+				Type:     "object",
+				Required: []string{"name", "label", "tags", "credentials"},
+				Properties: map[string]*Schema{
+					"name":             {Type: "string", Pattern: nonEmptyPattern},
+					"label":            {Type: "string", Pattern: nonEmptyPattern},
+					"plan":             {Type: "string", Pattern: nonEmptyPattern},
+					"provider":         {},
+					"syslog_drain_url": {},
+					"tags":             {Type: "array", Items: &Schema{Type: "string"}},
+					"credentials":      {Type: "object"},
+					"volume_mounts":    {Type: "array", Items: &Schema{Type: "object"}},
+				},
+			},
+		},
+	}
+}
+
+// InstanceEnvSchema describes the CF_INSTANCE_* and PORT variables of a
+// running instance, keyed by variable name.
+func InstanceEnvSchema() *Schema {
+	return &Schema{
+		Type: "object",
+		Required: []string{
+			"CF_INSTANCE_ADDR",
+			"CF_INSTANCE_GUID",
+			"CF_INSTANCE_INDEX",
+			"CF_INSTANCE_INTERNAL_IP",
+			"CF_INSTANCE_IP",
+			"CF_INSTANCE_PORT",
+			"CF_INSTANCE_PORTS",
+			"PORT",
+		},
+		Properties: map[string]*Schema{
+			"CF_INSTANCE_ADDR":        {Type: "string", Pattern: addressPattern},
+			"CF_INSTANCE_GUID":        {Type: "string", Pattern: nonEmptyPattern},
+			"CF_INSTANCE_INDEX":       {Type: "string", Pattern: portPattern},
+			"CF_INSTANCE_INTERNAL_IP": {Type: "string", Pattern: ipPattern},
+			"CF_INSTANCE_IP":          {Type: "string", Pattern: ipPattern},
+			"CF_INSTANCE_PORT":        {Type: "string", Pattern: portPattern},
+			"CF_INSTANCE_PORTS":       {Type: "string", Pattern: `^\[.*\]$`},
+			"PORT":                    {Type: "string", Pattern: portPattern},
+		},
+	}
+}
+
+// InstancePortsSchema describes the decoded value of CF_INSTANCE_PORTS.
+func InstancePortsSchema() *Schema {
+	return &Schema{
+		Type: "array",
+		Items: &Schema{
+			Type:     "object",
+			Required: []string{"external", "internal"},
+			Properties: map[string]*Schema{
+				"external": {Type: "integer", Minimum: minimum(1)},
+				"internal": {Type: "integer", Minimum: minimum(1)},
+			},
+		},
+	}
+}
+
+func stringMatching(expected string) *Schema {
+	schema := &Schema{Type: "string", Pattern: nonEmptyPattern}
+	if expected != "" {
+		schema.Enum = []interface{}{expected}
+	}
+	return schema
+}
+
+func minimum(value float64) *float64 {
+	return &value
+}
@@ -0,0 +1,57 @@
+package feature_flag_helpers
+
+import (
+	"encoding/json"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/workflowhelpers"
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+)
+
+type featureFlag struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// FetchFeatureFlags returns whether each feature flag is enabled, by name.
+func FetchFeatureFlags() map[string]bool {
+	var session *Session
+	workflowhelpers.AsUser(TestSetup.AdminUserContext(), Config.DefaultTimeoutDuration(), func() {
+		session = cf.Cf("curl", "/v2/config/feature_flags").Wait(Config.DefaultTimeoutDuration())
+		Expect(session).To(Exit(0))
+	})
+
+	var featureFlags []featureFlag
+	err := json.Unmarshal(session.Out.Contents(), &featureFlags)
+	Expect(err).NotTo(HaveOccurred())
+
+	flags := make(map[string]bool)
+	for _, flag := range featureFlags {
+		flags[flag.Name] = flag.Enabled
+	}
+	return flags
+}
+
+func SetFeatureFlag(name string, enabled bool) {
+	command := "disable-feature-flag"
+	if enabled {
+		command = "enable-feature-flag"
+	}
+
+	workflowhelpers.AsUser(TestSetup.AdminUserContext(), Config.DefaultTimeoutDuration(), func() {
+		Expect(cf.Cf(command, name).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+	})
+}
+
+// RestoreFeatureFlags sets the flags in snapshot that have changed since it
+// was fetched back to their values in it.
+func RestoreFeatureFlags(snapshot map[string]bool) {
+	current := FetchFeatureFlags()
+	for name, enabled := range snapshot {
+		if current[name] != enabled {
+			SetFeatureFlag(name, enabled)
+		}
+	}
+}
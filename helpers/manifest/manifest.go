@@ -0,0 +1,257 @@
+package manifest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+type Manifest struct {
+	Inherit      string            `yaml:"inherit,omitempty"`
+	Buildpack    string            `yaml:"buildpack,omitempty"`
+	Memory       string            `yaml:"memory,omitempty"`
+	Env          map[string]string `yaml:"env,omitempty"`
+	Applications []Application     `yaml:"applications,omitempty"`
+}
+
+type Application struct {
+	Name                    string            `yaml:"name"`
+	Path                    string            `yaml:"path,omitempty"`
+	Buildpack               string            `yaml:"buildpack,omitempty"`
+	Command                 string            `yaml:"command,omitempty"`
+	Memory                  string            `yaml:"memory,omitempty"`
+	DiskQuota               string            `yaml:"disk_quota,omitempty"`
+	Instances               int               `yaml:"instances,omitempty"`
+	Stack                   string            `yaml:"stack,omitempty"`
+	HealthCheckType         string            `yaml:"health-check-type,omitempty"`
+	HealthCheckHTTPEndpoint string            `yaml:"health-check-http-endpoint,omitempty"`
+	Env                     map[string]string `yaml:"env,omitempty"`
+	Routes                  []Route           `yaml:"routes,omitempty"`
+	NoRoute                 bool              `yaml:"no-route,omitempty"`
+	Services                []string          `yaml:"services,omitempty"`
+}
+
+type Route struct {
+	Route string `yaml:"route"`
+}
+
+func New(applications ...Application) Manifest {
+	return Manifest{Applications: applications}
+}
+
+func NewApplication(name string) Application {
+	return Application{Name: name}
+}
+
+func (m Manifest) InheritingFrom(path string) Manifest {
+	m.Inherit = path
+	return m
+}
+
+func (m Manifest) WithBuildpack(buildpack string) Manifest {
+	m.Buildpack = buildpack
+	return m
+}
+
+func (m Manifest) WithMemory(memory string) Manifest {
+	m.Memory = memory
+	return m
+}
+
+func (m Manifest) WithEnv(key, value string) Manifest {
+	m.Env = withEnv(m.Env, key, value)
+	return m
+}
+
+func (a Application) WithPath(path string) Application {
+	a.Path = path
+	return a
+}
+
+func (a Application) WithBuildpack(buildpack string) Application {
+	a.Buildpack = buildpack
+	return a
+}
+
+func (a Application) WithCommand(command string) Application {
+	a.Command = command
+	return a
+}
+
+func (a Application) WithMemory(memory string) Application {
+	a.Memory = memory
+	return a
+}
+
+func (a Application) WithDiskQuota(diskQuota string) Application {
+	a.DiskQuota = diskQuota
+	return a
+}
+
+func (a Application) WithInstances(instances int) Application {
+	a.Instances = instances
+	return a
+}
+
+func (a Application) WithHealthCheck(healthCheckType string) Application {
+	a.HealthCheckType = healthCheckType
+	return a
+}
+
+func (a Application) WithHTTPHealthCheck(endpoint string) Application {
+	a.HealthCheckType = "http"
+	a.HealthCheckHTTPEndpoint = endpoint
+	return a
+}
+
+func (a Application) WithEnv(key, value string) Application {
+	a.Env = withEnv(a.Env, key, value)
+	return a
+}
+
+func (a Application) WithRoute(route string) Application {
+	a.Routes = append(append([]Route{}, a.Routes...), Route{Route: route})
+	return a
+}
+
+func (a Application) WithNoRoute() Application {
+	a.NoRoute = true
+	return a
+}
+
+func (a Application) WithService(service string) Application {
+	a.Services = append(append([]string{}, a.Services...), service)
+	return a
+}
+
+func withEnv(env map[string]string, key, value string) map[string]string {
+	copied := map[string]string{key: value}
+	for k, v := range env {
+		if k != key {
+			copied[k] = v
+		}
+	}
+	return copied
+}
+
+func (m Manifest) Marshal() ([]byte, error) {
+	return yaml.Marshal(m)
+}
+
+// WriteTo writes the manifest into dir under filename and returns its path.
+func (m Manifest) WriteTo(dir, filename string) (string, error) {
+	contents, err := m.Marshal()
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, filename)
+	return path, ioutil.WriteFile(path, contents, 0644)
+}
+
+func Parse(contents []byte) (Manifest, error) {
+	var m Manifest
+	err := yaml.Unmarshal(contents, &m)
+	return m, err
+}
+
+func ParseFile(path string) (Manifest, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Manifest{}, err
+	}
+	return Parse(contents)
+}
+
+func (m Manifest) Application(name string) (Application, bool) {
+	for _, app := range m.Applications {
+		if app.Name == name {
+			return app, true
+		}
+	}
+	return Application{}, false
+}
+
+// Contains checks that every attribute set on the application has a
+// semantically equal value on actual. Sizes are compared in megabytes, and
+// routes and services are compared as sets; unset attributes are ignored, so
+// platform defaults in a generated manifest do not matter.
+func (a Application) Contains(actual Application) error {
+	var diffs []string
+	compare := func(field, expectedValue, actualValue string) {
+		if expectedValue != "" && expectedValue != actualValue {
+			diffs = append(diffs, fmt.Sprintf("%s: expected %q, got %q", field, expectedValue, actualValue))
+		}
+	}
+
+	compare("name", a.Name, actual.Name)
+	compare("buildpack", a.Buildpack, actual.Buildpack)
+	compare("command", a.Command, actual.Command)
+	compare("memory", megabytes(a.Memory), megabytes(actual.Memory))
+	compare("disk_quota", megabytes(a.DiskQuota), megabytes(actual.DiskQuota))
+	compare("stack", a.Stack, actual.Stack)
+	compare("health-check-type", a.HealthCheckType, actual.HealthCheckType)
+	compare("health-check-http-endpoint", a.HealthCheckHTTPEndpoint, actual.HealthCheckHTTPEndpoint)
+
+	if a.Instances != 0 && a.Instances != actual.Instances {
+		diffs = append(diffs, fmt.Sprintf("instances: expected %d, got %d", a.Instances, actual.Instances))
+	}
+
+	for key, value := range a.Env {
+		compare("env."+key, value, actual.Env[key])
+	}
+
+	if len(a.Routes) > 0 {
+		compare("routes", strings.Join(routeSet(a.Routes), ","), strings.Join(routeSet(actual.Routes), ","))
+	}
+	if len(a.Services) > 0 {
+		compare("services", strings.Join(set(a.Services), ","), strings.Join(set(actual.Services), ","))
+	}
+
+	if len(diffs) > 0 {
+		sort.Strings(diffs)
+		return fmt.Errorf("application %q does not match:\n  %s", a.Name, strings.Join(diffs, "\n  "))
+	}
+	return nil
+}
+
+func routeSet(routes []Route) []string {
+	values := []string{}
+	for _, route := range routes {
+		values = append(values, strings.ToLower(route.Route))
+	}
+	return set(values)
+}
+
+func set(values []string) []string {
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+// megabytes normalizes sizes such as "1G", "1024M" or "1024MB" to a count
+// of megabytes. Values it cannot parse are returned unchanged.
+func megabytes(size string) string {
+	normalized := strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(size)), "B")
+	multiplier := 1
+	switch {
+	case strings.HasSuffix(normalized, "G"):
+		multiplier = 1024
+		normalized = strings.TrimSuffix(normalized, "G")
+	case strings.HasSuffix(normalized, "M"):
+		normalized = strings.TrimSuffix(normalized, "M")
+	default:
+		return size
+	}
+
+	value, err := strconv.Atoi(normalized)
+	if err != nil {
+		return size
+	}
+	return strconv.Itoa(value * multiplier)
+}
@@ -0,0 +1,138 @@
+package manifest_test
+
+import (
+	"io/ioutil"
+	"os"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/manifest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Manifest", func() {
+	Describe("building and writing", func() {
+		var tmpDir string
+
+		BeforeEach(func() {
+			var err error
+			tmpDir, err = ioutil.TempDir("", "manifest-test")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(tmpDir)
+		})
+
+		It("round-trips through YAML", func() {
+			m := New(
+				NewApplication("app-1").
+					WithPath("/tmp/dora").
+					WithBuildpack("ruby_buildpack").
+					WithMemory("256M").
+					WithInstances(2).
+					WithHTTPHealthCheck("/health").
+					WithEnv("FOO", "bar").
+					WithRoute("app-1.example.com").
+					WithService("my-db"),
+				NewApplication("app-2").WithHealthCheck("process"),
+			).InheritingFrom("base.yml")
+
+			path, err := m.WriteTo(tmpDir, "manifest.yml")
+			Expect(err).NotTo(HaveOccurred())
+
+			parsed, err := ParseFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parsed).To(Equal(m))
+		})
+
+		It("omits unset attributes", func() {
+			contents, err := New(NewApplication("app")).Marshal()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal("applications:\n- name: app\n"))
+		})
+
+		It("does not share state between copies", func() {
+			base := NewApplication("app").WithEnv("A", "1").WithRoute("a.example.com")
+			derived := base.WithEnv("B", "2").WithRoute("b.example.com")
+
+			Expect(base.Env).To(Equal(map[string]string{"A": "1"}))
+			Expect(base.Routes).To(HaveLen(1))
+			Expect(derived.Env).To(Equal(map[string]string{"A": "1", "B": "2"}))
+			Expect(derived.Routes).To(HaveLen(2))
+		})
+	})
+
+	Describe("Application", func() {
+		It("finds applications by name", func() {
+			m := New(NewApplication("a"), NewApplication("b").WithMemory("64M"))
+
+			app, ok := m.Application("b")
+			Expect(ok).To(BeTrue())
+			Expect(app.Memory).To(Equal("64M"))
+
+			_, ok = m.Application("c")
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("Contains", func() {
+		generated := []byte(`applications:
+- name: app
+  buildpack: ruby_buildpack
+  memory: 1G
+  disk_quota: 1024M
+  instances: 2
+  stack: cflinuxfs2
+  health-check-type: port
+  env:
+    FOO: bar
+    EXTRA: value
+  routes:
+  - route: b.example.com
+  - route: A.example.com
+  services:
+  - svc-2
+  - svc-1
+`)
+
+		var actual Application
+
+		BeforeEach(func() {
+			m, err := Parse(generated)
+			Expect(err).NotTo(HaveOccurred())
+			actual = m.Applications[0]
+		})
+
+		It("ignores attributes that were not specified", func() {
+			Expect(NewApplication("app").Contains(actual)).To(Succeed())
+		})
+
+		It("compares sizes, routes and services semantically", func() {
+			expected := NewApplication("app").
+				WithMemory("1024M").
+				WithDiskQuota("1GB").
+				WithRoute("a.example.com").
+				WithRoute("b.example.com").
+				WithService("svc-1").
+				WithService("svc-2").
+				WithEnv("FOO", "bar")
+			Expect(expected.Contains(actual)).To(Succeed())
+		})
+
+		It("reports every mismatch", func() {
+			expected := NewApplication("app").
+				WithMemory("512M").
+				WithInstances(3).
+				WithHealthCheck("process").
+				WithEnv("FOO", "baz")
+
+			err := expected.Contains(actual)
+			Expect(err).To(MatchError(`application "app" does not match:
+  env.FOO: expected "baz", got "bar"
+  health-check-type: expected "process", got "port"
+  instances: expected 3, got 2
+  memory: expected "512", got "1024"`))
+		})
+	})
+})
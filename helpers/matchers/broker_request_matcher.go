@@ -0,0 +1,282 @@
+package matchers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/assets/go-service-broker/broker"
+)
+
+var (
+	serviceInstancePath = regexp.MustCompile(`^/v2/service_instances/[^/]+$`)
+	serviceBindingPath  = regexp.MustCompile(`^/v2/service_instances/[^/]+/service_bindings/[^/]+$`)
+)
+
+// HaveReceivedProvision succeeds if a journal of broker requests, as returned
+// by ServiceBroker.Requests, contains a provision request for planID.
+func HaveReceivedProvision(planID string) *BrokerRequestMatcher {
+	return newBrokerRequestMatcher("provision", "PUT", serviceInstancePath, planID)
+}
+
+// HaveReceivedUpdate succeeds if the journal contains an update request. An
+// empty planID matches updates that do not change the plan as well.
+func HaveReceivedUpdate(planID string) *BrokerRequestMatcher {
+	return newBrokerRequestMatcher("update", "PATCH", serviceInstancePath, planID)
+}
+
+func HaveReceivedDeprovision(planID string) *BrokerRequestMatcher {
+	return newBrokerRequestMatcher("deprovision", "DELETE", serviceInstancePath, planID)
+}
+
+func HaveReceivedBind(planID string) *BrokerRequestMatcher {
+	return newBrokerRequestMatcher("bind", "PUT", serviceBindingPath, planID)
+}
+
+func HaveReceivedUnbind(planID string) *BrokerRequestMatcher {
+	return newBrokerRequestMatcher("unbind", "DELETE", serviceBindingPath, planID)
+}
+
+// HaveReceivedBindingFetch succeeds if the journal contains a request for the
+// binding with the given id, as sent once an asynchronous bind has finished.
+func HaveReceivedBindingFetch(bindingID string) *BrokerRequestMatcher {
+	pattern := regexp.MustCompile(`^/v2/service_instances/[^/]+/service_bindings/` + regexp.QuoteMeta(bindingID) + `$`)
+	return newBrokerRequestMatcher("fetch of binding "+bindingID, "GET", pattern, "")
+}
+
+func newBrokerRequestMatcher(operation, method string, pattern *regexp.Regexp, planID string) *BrokerRequestMatcher {
+	return &BrokerRequestMatcher{
+		operation: operation,
+		method:    method,
+		path:      pattern,
+		planID:    planID,
+		query:     map[string]string{},
+		headers:   map[string]string{},
+	}
+}
+
+type BrokerRequestMatcher struct {
+	operation string
+	method    string
+	path      *regexp.Regexp
+	planID    string
+	id        string
+
+	parameters          interface{}
+	hasParameters       bool
+	query               map[string]string
+	headers             map[string]string
+	originatingIdentity map[string]interface{}
+}
+
+// WithParameters requires the request body to carry exactly these
+// user-provided parameters.
+func (matcher *BrokerRequestMatcher) WithParameters(parameters interface{}) *BrokerRequestMatcher {
+	matcher.parameters = normalizeJSON(parameters)
+	matcher.hasParameters = true
+	return matcher
+}
+
+// ForID requires the request to be for the service instance or binding with
+// the given id.
+func (matcher *BrokerRequestMatcher) ForID(id string) *BrokerRequestMatcher {
+	matcher.id = id
+	return matcher
+}
+
+func (matcher *BrokerRequestMatcher) WithQueryParam(name, value string) *BrokerRequestMatcher {
+	matcher.query[name] = value
+	return matcher
+}
+
+func (matcher *BrokerRequestMatcher) WithAcceptsIncomplete() *BrokerRequestMatcher {
+	return matcher.WithQueryParam("accepts_incomplete", "true")
+}
+
+func (matcher *BrokerRequestMatcher) WithHeader(name, value string) *BrokerRequestMatcher {
+	matcher.headers[http.CanonicalHeaderKey(name)] = value
+	return matcher
+}
+
+func (matcher *BrokerRequestMatcher) WithAPIVersion(version string) *BrokerRequestMatcher {
+	return matcher.WithHeader("X-Broker-API-Version", version)
+}
+
+// WithOriginatingIdentity requires an X-Broker-API-Originating-Identity header
+// for the given platform whose decoded value contains identity, for example
+// map[string]interface{}{"user_id": userGuid}.
+func (matcher *BrokerRequestMatcher) WithOriginatingIdentity(platform string, identity map[string]interface{}) *BrokerRequestMatcher {
+	matcher.originatingIdentity = map[string]interface{}{
+		"platform": platform,
+		"identity": normalizeJSON(identity),
+	}
+	return matcher
+}
+
+func (matcher *BrokerRequestMatcher) Match(actual interface{}) (success bool, err error) {
+	requests, ok := actual.([]broker.Request)
+	if !ok {
+		return false, fmt.Errorf("BrokerRequestMatcher matcher: actual value must be a []broker.Request")
+	}
+
+	for _, request := range requests {
+		if matcher.matches(request) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (matcher *BrokerRequestMatcher) FailureMessage(actual interface{}) (message string) {
+	return fmt.Sprintf("Expected broker to have received %s\nReceived:\n%s", matcher.describe(), describeRequests(actual))
+}
+
+func (matcher *BrokerRequestMatcher) NegatedFailureMessage(actual interface{}) (message string) {
+	return fmt.Sprintf("Expected broker not to have received %s\nReceived:\n%s", matcher.describe(), describeRequests(actual))
+}
+
+func (matcher *BrokerRequestMatcher) matches(request broker.Request) bool {
+	if request.Method != matcher.method || !matcher.path.MatchString(request.Path) {
+		return false
+	}
+
+	if matcher.id != "" && path.Base(request.Path) != matcher.id {
+		return false
+	}
+
+	var body map[string]interface{}
+	if request.Body != "" {
+		if err := json.Unmarshal([]byte(request.Body), &body); err != nil {
+			return false
+		}
+	}
+
+	if matcher.planID != "" {
+		planID, _ := body["plan_id"].(string)
+		if matcher.method == "DELETE" {
+			planID = firstValue(request.Query, "plan_id")
+		}
+		if planID != matcher.planID {
+			return false
+		}
+	}
+
+	if matcher.hasParameters && !reflect.DeepEqual(body["parameters"], matcher.parameters) {
+		return false
+	}
+
+	for name, value := range matcher.query {
+		if firstValue(request.Query, name) != value {
+			return false
+		}
+	}
+
+	for name, value := range matcher.headers {
+		if firstValue(request.Headers, name) != value {
+			return false
+		}
+	}
+
+	if matcher.originatingIdentity != nil {
+		return matchesOriginatingIdentity(firstValue(request.Headers, "X-Broker-Api-Originating-Identity"), matcher.originatingIdentity)
+	}
+	return true
+}
+
+func (matcher *BrokerRequestMatcher) describe() string {
+	description := matcher.operation
+	if matcher.id != "" {
+		description += " of " + matcher.id
+	}
+	if matcher.planID != "" {
+		description += " for plan " + matcher.planID
+	}
+	if matcher.hasParameters {
+		description += fmt.Sprintf(" with parameters %v", matcher.parameters)
+	}
+	for name, value := range matcher.query {
+		description += fmt.Sprintf(" with %s=%s", name, value)
+	}
+	for name, value := range matcher.headers {
+		description += fmt.Sprintf(" with header %s: %s", name, value)
+	}
+	if matcher.originatingIdentity != nil {
+		description += fmt.Sprintf(" with originating identity %v", matcher.originatingIdentity)
+	}
+	return description
+}
+
+// matchesOriginatingIdentity checks a header of the form
+// "<platform> <base64-encoded JSON>" against the expected platform and the
+// expected subset of the identity.
+func matchesOriginatingIdentity(header string, expected map[string]interface{}) bool {
+	parts := strings.SplitN(header, " ", 2)
+	if len(parts) != 2 || parts[0] != expected["platform"] {
+		return false
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return false
+	}
+
+	var identity map[string]interface{}
+	if err := json.Unmarshal(decoded, &identity); err != nil {
+		return false
+	}
+
+	for key, value := range expected["identity"].(map[string]interface{}) {
+		if !reflect.DeepEqual(identity[key], value) {
+			return false
+		}
+	}
+	return true
+}
+
+func describeRequests(actual interface{}) string {
+	requests, _ := actual.([]broker.Request)
+	if len(requests) == 0 {
+		return "\t<none>"
+	}
+
+	lines := []string{}
+	for _, request := range requests {
+		line := "\t" + request.Method + " " + request.Path
+		if len(request.Query) > 0 {
+			line += "?" + url.Values(request.Query).Encode()
+		}
+		if request.Body != "" {
+			line += " " + request.Body
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func firstValue(values map[string][]string, name string) string {
+	if found, ok := values[name]; ok && len(found) > 0 {
+		return found[0]
+	}
+	return ""
+}
+
+// normalizeJSON round-trips value through JSON so that it compares equal to
+// the same value decoded from a request body.
+func normalizeJSON(value interface{}) interface{} {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+
+	var normalized interface{}
+	if err := json.Unmarshal(encoded, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
@@ -0,0 +1,99 @@
+package matchers_test
+
+import (
+	"encoding/base64"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/matchers"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/assets/go-service-broker/broker"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BrokerRequestMatcher", func() {
+	var requests []broker.Request
+
+	BeforeEach(func() {
+		identity := base64.StdEncoding.EncodeToString([]byte(`{"user_id":"user-guid"}`))
+
+		requests = []broker.Request{
+			{
+				Method: "GET",
+				Path:   "/v2/catalog",
+			},
+			{
+				Method: "PUT",
+				Path:   "/v2/service_instances/instance-guid",
+				Query:  map[string][]string{"accepts_incomplete": {"true"}},
+				Headers: map[string][]string{
+					"X-Broker-Api-Version":              {"2.13"},
+					"X-Broker-Api-Originating-Identity": {"cloudfoundry " + identity},
+				},
+				Body: `{"plan_id":"plan-guid","parameters":{"size":3,"name":"my-db"}}`,
+			},
+			{
+				Method: "PUT",
+				Path:   "/v2/service_instances/instance-guid/service_bindings/binding-guid",
+				Body:   `{"plan_id":"plan-guid"}`,
+			},
+			{
+				Method: "GET",
+				Path:   "/v2/service_instances/instance-guid/service_bindings/binding-guid",
+			},
+			{
+				Method: "DELETE",
+				Path:   "/v2/service_instances/instance-guid/service_bindings/binding-guid",
+				Query:  map[string][]string{"plan_id": {"plan-guid"}},
+			},
+		}
+	})
+
+	It("matches requests by operation and plan", func() {
+		Expect(requests).To(HaveReceivedProvision("plan-guid"))
+		Expect(requests).To(HaveReceivedBind("plan-guid"))
+		Expect(requests).To(HaveReceivedUnbind("plan-guid"))
+		Expect(requests).To(HaveReceivedBindingFetch("binding-guid"))
+
+		Expect(requests).NotTo(HaveReceivedBindingFetch("other-binding-guid"))
+		Expect(requests).NotTo(HaveReceivedProvision("other-plan-guid"))
+		Expect(requests).NotTo(HaveReceivedDeprovision(""))
+		Expect(requests).NotTo(HaveReceivedUpdate(""))
+	})
+
+	It("matches the id of the instance or binding", func() {
+		Expect(requests).To(HaveReceivedProvision("plan-guid").ForID("instance-guid"))
+		Expect(requests).To(HaveReceivedUnbind("plan-guid").ForID("binding-guid"))
+		Expect(requests).NotTo(HaveReceivedProvision("plan-guid").ForID("binding-guid"))
+	})
+
+	It("matches parameters regardless of their Go types", func() {
+		Expect(requests).To(HaveReceivedProvision("plan-guid").WithParameters(map[string]interface{}{"size": 3, "name": "my-db"}))
+		Expect(requests).NotTo(HaveReceivedProvision("plan-guid").WithParameters(map[string]interface{}{"size": 3}))
+		Expect(requests).NotTo(HaveReceivedBind("plan-guid").WithParameters(map[string]interface{}{"size": 3}))
+	})
+
+	It("matches query parameters and headers", func() {
+		Expect(requests).To(HaveReceivedProvision("plan-guid").WithAcceptsIncomplete().WithAPIVersion("2.13"))
+		Expect(requests).To(HaveReceivedProvision("plan-guid").WithHeader("x-broker-api-version", "2.13"))
+		Expect(requests).NotTo(HaveReceivedBind("plan-guid").WithAcceptsIncomplete())
+		Expect(requests).NotTo(HaveReceivedProvision("plan-guid").WithAPIVersion("2.10"))
+	})
+
+	It("decodes the originating identity", func() {
+		Expect(requests).To(HaveReceivedProvision("plan-guid").WithOriginatingIdentity("cloudfoundry", map[string]interface{}{"user_id": "user-guid"}))
+		Expect(requests).NotTo(HaveReceivedProvision("plan-guid").WithOriginatingIdentity("cloudfoundry", map[string]interface{}{"user_id": "someone-else"}))
+		Expect(requests).NotTo(HaveReceivedBind("plan-guid").WithOriginatingIdentity("cloudfoundry", map[string]interface{}{"user_id": "user-guid"}))
+	})
+
+	It("lists the received requests when it fails", func() {
+		matcher := HaveReceivedDeprovision("plan-guid")
+		Expect(matcher.Match(requests)).To(BeFalse())
+		Expect(matcher.FailureMessage(requests)).To(ContainSubstring("deprovision for plan plan-guid"))
+		Expect(matcher.FailureMessage(requests)).To(ContainSubstring("PUT /v2/service_instances/instance-guid?accepts_incomplete=true"))
+	})
+
+	It("errors when actual is not a request journal", func() {
+		_, err := HaveReceivedProvision("plan-guid").Match("not a journal")
+		Expect(err).To(HaveOccurred())
+	})
+})
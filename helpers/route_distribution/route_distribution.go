@@ -0,0 +1,157 @@
+package route_distribution
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/onsi/gomega/types"
+)
+
+// Histogram counts the requests served by each backend of a route, keyed by
+// app name and instance index as returned by Backend.
+type Histogram map[string]int
+
+// Backend identifies an instance of an app.
+func Backend(appName string, index int) string {
+	return fmt.Sprintf("%s/%d", appName, index)
+}
+
+// Backends returns the backends of instances 0 to instances-1 of an app.
+func Backends(appName string, instances int) []string {
+	backends := []string{}
+	for index := 0; index < instances; index++ {
+		backends = append(backends, Backend(appName, index))
+	}
+	return backends
+}
+
+var helloRoutingBody = regexp.MustCompile(`Hello, (\S+) at index: (\d+)!`)
+
+// HelloRoutingBackend returns the backend that served a response of the
+// hello-routing asset, or the empty string if body is not such a response.
+func HelloRoutingBackend(body string) string {
+	matches := helloRoutingBody.FindStringSubmatch(body)
+	if matches == nil {
+		return ""
+	}
+	return matches[1] + "/" + matches[2]
+}
+
+// Sample sends n requests and counts the backend that served each of them.
+func Sample(n int, request func() string, backend func(body string) string) Histogram {
+	histogram := Histogram{}
+	for i := 0; i < n; i++ {
+		histogram[backend(request())]++
+	}
+	return histogram
+}
+
+// Total returns the number of requests in the histogram.
+func (h Histogram) Total() int {
+	total := 0
+	for _, count := range h {
+		total += count
+	}
+	return total
+}
+
+// Unexpected returns the keys of the histogram that are not in backends,
+// sorted.
+func (h Histogram) Unexpected(backends []string) []string {
+	expected := map[string]bool{}
+	for _, backend := range backends {
+		expected[backend] = true
+	}
+
+	unexpected := []string{}
+	for key := range h {
+		if !expected[key] {
+			unexpected = append(unexpected, key)
+		}
+	}
+	sort.Strings(unexpected)
+	return unexpected
+}
+
+// ChiSquare returns Pearson's chi-square statistic of the counts of backends
+// against an even distribution of all requests across them.
+func (h Histogram) ChiSquare(backends []string) float64 {
+	expected := float64(h.Total()) / float64(len(backends))
+
+	statistic := 0.0
+	for _, backend := range backends {
+		deviation := float64(h[backend]) - expected
+		statistic += deviation * deviation / expected
+	}
+	return statistic
+}
+
+// chiSquareCriticalValues are the critical values of the chi-square
+// distribution at a significance level of 0.001, by degrees of freedom.
+var chiSquareCriticalValues = []float64{
+	1:  10.828,
+	2:  13.816,
+	3:  16.266,
+	4:  18.467,
+	5:  20.515,
+	6:  22.458,
+	7:  24.322,
+	8:  26.124,
+	9:  27.877,
+	10: 29.588,
+}
+
+// CriticalValue returns the chi-square statistic above which a distribution
+// across the given number of backends is not even, at a significance level of
+// 0.001. Beyond the table, it uses the Wilson-Hilferty approximation.
+func CriticalValue(backends int) float64 {
+	degreesOfFreedom := backends - 1
+	if degreesOfFreedom < len(chiSquareCriticalValues) {
+		return chiSquareCriticalValues[degreesOfFreedom]
+	}
+
+	const z = 3.090 // the 0.999 quantile of the standard normal distribution
+	k := float64(degreesOfFreedom)
+	return k * math.Pow(1-2/(9*k)+z*math.Sqrt(2/(9*k)), 3)
+}
+
+// BeEvenlyDistributedAcross succeeds if a Histogram has counts for the given
+// backends only, and the chi-square test does not reject an even
+// distribution across them.
+func BeEvenlyDistributedAcross(backends ...string) types.GomegaMatcher {
+	return &evenDistributionMatcher{backends: backends}
+}
+
+type evenDistributionMatcher struct {
+	backends []string
+}
+
+func (matcher *evenDistributionMatcher) Match(actual interface{}) (bool, error) {
+	histogram, ok := actual.(Histogram)
+	if !ok {
+		return false, fmt.Errorf("BeEvenlyDistributedAcross matcher: actual value must be a Histogram")
+	}
+	if len(matcher.backends) < 2 {
+		return false, fmt.Errorf("BeEvenlyDistributedAcross matcher: at least two backends are required")
+	}
+
+	if len(histogram.Unexpected(matcher.backends)) > 0 {
+		return false, nil
+	}
+	return histogram.ChiSquare(matcher.backends) <= CriticalValue(len(matcher.backends)), nil
+}
+
+func (matcher *evenDistributionMatcher) FailureMessage(actual interface{}) string {
+	histogram := actual.(Histogram)
+	if unexpected := histogram.Unexpected(matcher.backends); len(unexpected) > 0 {
+		return fmt.Sprintf("Expected\n\t%#v\nto only have counts for\n\t%s\nbut it has counts for\n\t%q", actual, strings.Join(matcher.backends, ", "), unexpected)
+	}
+	return fmt.Sprintf("Expected\n\t%#v\nto be evenly distributed across\n\t%s\nbut its chi-square statistic %.2f exceeds %.2f", actual, strings.Join(matcher.backends, ", "), histogram.ChiSquare(matcher.backends), CriticalValue(len(matcher.backends)))
+}
+
+func (matcher *evenDistributionMatcher) NegatedFailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected\n\t%#v\nnot to be evenly distributed across\n\t%s", actual, strings.Join(matcher.backends, ", "))
+}
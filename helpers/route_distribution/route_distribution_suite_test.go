@@ -0,0 +1,13 @@
+package route_distribution_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestRouteDistribution(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "RouteDistribution Suite")
+}
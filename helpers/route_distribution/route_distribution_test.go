@@ -0,0 +1,77 @@
+package route_distribution_test
+
+import (
+	"fmt"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/route_distribution"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RouteDistribution", func() {
+	Describe("HelloRoutingBackend", func() {
+		It("identifies the app and instance that served the response", func() {
+			Expect(HelloRoutingBackend("Hello, my-app at index: 2!")).To(Equal(Backend("my-app", 2)))
+		})
+
+		It("returns the empty string for other responses", func() {
+			Expect(HelloRoutingBackend("502 Bad Gateway")).To(BeEmpty())
+		})
+	})
+
+	Describe("Sample", func() {
+		It("counts the backend of each response", func() {
+			requests := 0
+			histogram := Sample(6, func() string {
+				requests++
+				return fmt.Sprintf("Hello, my-app at index: %d!", requests%2)
+			}, HelloRoutingBackend)
+
+			Expect(histogram).To(Equal(Histogram{"my-app/0": 3, "my-app/1": 3}))
+			Expect(histogram.Total()).To(Equal(6))
+		})
+	})
+
+	Describe("ChiSquare", func() {
+		It("is zero for a perfectly even distribution", func() {
+			Expect(Histogram{"a/0": 50, "a/1": 50}.ChiSquare([]string{"a/0", "a/1"})).To(BeZero())
+		})
+
+		It("counts backends that served no requests", func() {
+			Expect(Histogram{"a/0": 100}.ChiSquare([]string{"a/0", "a/1"})).To(Equal(100.0))
+		})
+	})
+
+	Describe("CriticalValue", func() {
+		It("uses the table for few backends", func() {
+			Expect(CriticalValue(2)).To(Equal(10.828))
+			Expect(CriticalValue(11)).To(Equal(29.588))
+		})
+
+		It("approximates the critical value for many backends", func() {
+			Expect(CriticalValue(21)).To(BeNumerically("~", 45.315, 0.5))
+		})
+	})
+
+	Describe("BeEvenlyDistributedAcross", func() {
+		backends := Backends("my-app", 4)
+
+		It("accepts the noise of round-robin routing", func() {
+			Expect(Histogram{"my-app/0": 27, "my-app/1": 23, "my-app/2": 25, "my-app/3": 25}).To(BeEvenlyDistributedAcross(backends...))
+		})
+
+		It("rejects a skewed distribution", func() {
+			Expect(Histogram{"my-app/0": 70, "my-app/1": 10, "my-app/2": 10, "my-app/3": 10}).NotTo(BeEvenlyDistributedAcross(backends...))
+		})
+
+		It("rejects responses from other backends", func() {
+			Expect(Histogram{"my-app/0": 25, "my-app/1": 25, "my-app/2": 25, "": 25}).NotTo(BeEvenlyDistributedAcross(backends[:3]...))
+		})
+
+		It("requires a Histogram", func() {
+			_, err := BeEvenlyDistributedAcross(backends...).Match(map[string]int{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
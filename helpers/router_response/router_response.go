@@ -0,0 +1,72 @@
+package router_response
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+)
+
+var (
+	statusLine       = regexp.MustCompile(`^HTTP/\S+ (\d{3})`)
+	routerErrorValue = regexp.MustCompile(`(?im)^X-Cf-Routererror:\s*(\S+)`)
+)
+
+// Response is the outcome of a request through the router. Statuses holds
+// the status of every response, including interim ones such as
+// 100 Continue. RouterError and Body are those of the final response.
+type Response struct {
+	Statuses    []int
+	RouterError string
+	Body        string
+}
+
+// Status returns the final status, or 0 if curl did not get a response.
+func (r Response) Status() int {
+	if len(r.Statuses) == 0 {
+		return 0
+	}
+	return r.Statuses[len(r.Statuses)-1]
+}
+
+// String summarizes the response by its status and X-Cf-Routererror header.
+func (r Response) String() string {
+	return strings.TrimSpace(fmt.Sprintf("%d %s", r.Status(), r.RouterError))
+}
+
+// Parse parses the output of `curl -i`.
+func Parse(output string) Response {
+	response := Response{}
+	for {
+		matches := statusLine.FindStringSubmatch(output)
+		if matches == nil {
+			return response
+		}
+		status, _ := strconv.Atoi(matches[1])
+		response.Statuses = append(response.Statuses, status)
+
+		parts := strings.SplitN(output, "\r\n\r\n", 2)
+		response.RouterError = ""
+		if matches := routerErrorValue.FindStringSubmatch(parts[0]); matches != nil {
+			response.RouterError = matches[1]
+		}
+		response.Body = ""
+		if len(parts) < 2 {
+			return response
+		}
+		output = parts[1]
+		response.Body = output
+	}
+}
+
+// Curl sends a request with the given curl arguments to the path of the app,
+// and waits for at most timeout for the response.
+func Curl(appName, path string, timeout time.Duration, args ...string) Response {
+	args = append([]string{"-s", "-i", "--max-time", strconv.Itoa(int(timeout.Seconds()))}, args...)
+	output := helpers.Curl(Config, append(args, helpers.AppUri(appName, path, Config))...).Wait(timeout + 10*time.Second).Out.Contents()
+	return Parse(string(output))
+}
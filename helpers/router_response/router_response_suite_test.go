@@ -0,0 +1,13 @@
+package router_response_test
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"testing"
+)
+
+func TestRouterResponse(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "RouterResponse Suite")
+}
@@ -0,0 +1,36 @@
+package router_response_test
+
+import (
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/router_response"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Parse", func() {
+	It("parses the status, router error and body of a response", func() {
+		response := Parse("HTTP/1.1 502 Bad Gateway\r\nX-Cf-Routererror: endpoint_failure\r\nContent-Length: 14\r\n\r\n502 Bad Gateway: Registered endpoint failed to handle the request.\n")
+
+		Expect(response.Statuses).To(Equal([]int{502}))
+		Expect(response.Status()).To(Equal(502))
+		Expect(response.RouterError).To(Equal("endpoint_failure"))
+		Expect(response.Body).To(HavePrefix("502 Bad Gateway"))
+		Expect(response.String()).To(Equal("502 endpoint_failure"))
+	})
+
+	It("keeps the interim statuses and takes the rest from the final response", func() {
+		response := Parse("HTTP/1.1 100 Continue\r\n\r\nHTTP/1.1 200 OK\r\nContent-Type: application/json\r\n\r\n{\"bytes\":5}")
+
+		Expect(response.Statuses).To(Equal([]int{100, 200}))
+		Expect(response.RouterError).To(BeEmpty())
+		Expect(response.Body).To(Equal(`{"bytes":5}`))
+		Expect(response.String()).To(Equal("200"))
+	})
+
+	It("returns status 0 when curl got no response", func() {
+		response := Parse("")
+
+		Expect(response.Status()).To(BeZero())
+		Expect(response.String()).To(Equal("0"))
+	})
+})
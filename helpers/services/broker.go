@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 
 	. "github.com/onsi/gomega"
@@ -41,6 +44,10 @@ type ServiceBroker struct {
 	}
 	SyncPlans  []Plan
 	AsyncPlans []Plan
+
+	// URL points at a broker started with StartLocal. When it is empty the
+	// broker is reached through the route of the pushed app.
+	URL string
 }
 
 type ServicesResponse struct {
@@ -105,10 +112,15 @@ func NewServiceBroker(name string, path string, TestSetup *workflowhelpers.Repro
 }
 
 func (b ServiceBroker) Push(config cats_config.CatsConfig) {
+	buildpack := config.GetRubyBuildpackName()
+	if _, err := os.Stat(filepath.Join(b.Path, "Godeps")); err == nil {
+		buildpack = config.GetGoBuildpackName()
+	}
+
 	Expect(cf.Cf(
 		"push", b.Name,
 		"--no-start",
-		"-b", config.GetRubyBuildpackName(),
+		"-b", buildpack,
 		"-m", DEFAULT_MEMORY_LIMIT,
 		"-p", b.Path,
 		"-d", config.GetAppsDomain(),
@@ -118,7 +130,61 @@ func (b ServiceBroker) Push(config cats_config.CatsConfig) {
 }
 
 func (b ServiceBroker) Configure() {
-	Expect(helpers.Curl(Config, helpers.AppUri(b.Name, "/config", Config), "-d", b.ToJSON()).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+	b.configureWith(b.ToJSON())
+}
+
+// ConfigureServiceMetadata configures the broker like Configure, with the
+// given keys overriding those in the metadata of the service in its catalog,
+// for example {"shareable": false}.
+func (b ServiceBroker) ConfigureServiceMetadata(metadata map[string]interface{}) {
+	var config map[string]interface{}
+	Expect(json.Unmarshal([]byte(b.ToJSON()), &config)).To(Succeed())
+
+	catalog := config["behaviors"].(map[string]interface{})["catalog"].(map[string]interface{})
+	service := catalog["body"].(map[string]interface{})["services"].([]interface{})[0].(map[string]interface{})
+	serviceMetadata := service["metadata"].(map[string]interface{})
+	for key, value := range metadata {
+		serviceMetadata[key] = value
+	}
+
+	configJSON, err := json.Marshal(config)
+	Expect(err).NotTo(HaveOccurred())
+	b.configureWith(string(configJSON))
+}
+
+// ConfigureBehavior makes the broker respond to every plan with behavior for
+// the given action, such as "provision", replacing any per-plan responses.
+func (b ServiceBroker) ConfigureBehavior(action string, behavior map[string]interface{}) {
+	configJSON, err := json.Marshal(map[string]interface{}{
+		"behaviors": map[string]interface{}{
+			action: map[string]interface{}{"default": behavior},
+		},
+	})
+	Expect(err).NotTo(HaveOccurred())
+	b.configureWith(string(configJSON))
+}
+
+// Reset discards the instances, bindings and behaviors left by earlier specs
+// by restoring the broker's data.json, then configures it like Configure.
+func (b ServiceBroker) Reset() {
+	b.post("/config/reset", "")
+	b.Configure()
+}
+
+func (b ServiceBroker) configureWith(configJSON string) {
+	b.post("/config", configJSON)
+}
+
+func (b ServiceBroker) post(path, body string) {
+	if b.URL != "" {
+		response, err := http.Post(b.URL+path, "application/json", strings.NewReader(body))
+		Expect(err).NotTo(HaveOccurred())
+		defer response.Body.Close()
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		return
+	}
+
+	Expect(helpers.Curl(Config, helpers.AppUri(b.Name, path, Config), "-d", body).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
 }
 
 func (b ServiceBroker) Restart() {
@@ -128,14 +194,14 @@ func (b ServiceBroker) Restart() {
 func (b ServiceBroker) Create() {
 	workflowhelpers.AsUser(b.TestSetup.AdminUserContext(), Config.DefaultTimeoutDuration(), func() {
 		Expect(cf.Cf("create-service-broker", b.Name, "username", "password", helpers.AppUri(b.Name, "", Config)).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
-		Expect(cf.Cf("service-brokers").Wait(Config.DefaultTimeoutDuration())).To(Say(b.Name))
+		Expect(cf.Cf("service-brokers").Wait(Config.DefaultTimeoutDuration())).To(Say("%s", b.Name))
 	})
 }
 
 func (b ServiceBroker) CreateSpaceScoped() {
 	workflowhelpers.AsUser(b.TestSetup.RegularUserContext(), Config.DefaultTimeoutDuration(), func() {
 		Expect(cf.Cf("create-service-broker", b.Name, "username", "password", helpers.AppUri(b.Name, "", Config), "--space-scoped").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
-		Expect(cf.Cf("service-brokers").Wait(Config.DefaultTimeoutDuration())).To(Say(b.Name))
+		Expect(cf.Cf("service-brokers").Wait(Config.DefaultTimeoutDuration())).To(Say("%s", b.Name))
 	})
 }
 
@@ -163,8 +229,15 @@ func (b ServiceBroker) Destroy() {
 	Expect(cf.Cf("delete", b.Name, "-f", "-r").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
 }
 
+// ToJSON fills in the broker's cats.json template, falling back to the one
+// of the Ruby broker for brokers that do not have their own.
 func (b ServiceBroker) ToJSON() string {
-	bytes, err := ioutil.ReadFile(assets.NewAssets().ServiceBroker + "/cats.json")
+	template := filepath.Join(b.Path, "cats.json")
+	if _, err := os.Stat(template); err != nil {
+		template = assets.NewAssets().ServiceBroker + "/cats.json"
+	}
+
+	bytes, err := ioutil.ReadFile(template)
 	Expect(err).To(BeNil())
 
 	replacer := strings.NewReplacer(
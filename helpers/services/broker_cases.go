@@ -0,0 +1,128 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var brokerCaseColumns = []string{"action", "sleep seconds", "status", "body", "expected output", "expected exit code"}
+
+// BrokerCase is a row of a broker failure-mode table such as
+// assets/service_broker/acceptance.csv. It describes how the broker responds
+// to an action, and what the CLI is expected to print and exit with. The
+// output column of the table is left to run_all_cases.rb, which records the
+// actual CLI output there.
+type BrokerCase struct {
+	Action           string
+	SleepSeconds     float64
+	Status           int
+	Body             string
+	ExpectedOutput   string
+	ExpectedExitCode int
+}
+
+// Behavior is the broker behavior to configure for the case's action.
+func (c BrokerCase) Behavior() map[string]interface{} {
+	return map[string]interface{}{
+		"sleep_seconds": c.SleepSeconds,
+		"status":        c.Status,
+		"raw_body":      c.Body,
+	}
+}
+
+func (c BrokerCase) String() string {
+	description := fmt.Sprintf("%s responding %d with %q", c.Action, c.Status, c.Body)
+	if c.SleepSeconds > 0 {
+		description += fmt.Sprintf(" after %gs", c.SleepSeconds)
+	}
+	return description
+}
+
+// LoadBrokerCases reads a broker case table. Lines starting with '#' are
+// commented out, which is how cases whose outcome has not been settled are
+// kept in the table.
+func LoadBrokerCases(path string) ([]BrokerCase, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%s: could not read header: %s", path, err)
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, name := range brokerCaseColumns {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("%s: missing column %q", path, name)
+		}
+	}
+
+	cases := []BrokerCase{}
+	for n := 1; ; n++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", path, err)
+		}
+
+		brokerCase, err := parseBrokerCase(columns, record)
+		if err != nil {
+			return nil, fmt.Errorf("%s: case %d: %s", path, n, err)
+		}
+		cases = append(cases, brokerCase)
+	}
+	return cases, nil
+}
+
+func parseBrokerCase(columns map[string]int, record []string) (BrokerCase, error) {
+	field := func(name string) string {
+		if columns[name] < len(record) {
+			return strings.TrimSpace(record[columns[name]])
+		}
+		return ""
+	}
+
+	brokerCase := BrokerCase{
+		Action:         field("action"),
+		Body:           field("body"),
+		ExpectedOutput: field("expected output"),
+	}
+
+	switch brokerCase.Action {
+	case "provision", "update", "deprovision":
+	default:
+		return brokerCase, fmt.Errorf("unknown action %q", brokerCase.Action)
+	}
+
+	if brokerCase.ExpectedOutput == "" {
+		return brokerCase, fmt.Errorf("expected output must not be empty")
+	}
+
+	var err error
+	if brokerCase.SleepSeconds, err = strconv.ParseFloat(field("sleep seconds"), 64); err != nil {
+		return brokerCase, fmt.Errorf("invalid sleep seconds: %s", err)
+	}
+	if brokerCase.Status, err = strconv.Atoi(field("status")); err != nil {
+		return brokerCase, fmt.Errorf("invalid status: %s", err)
+	}
+	if brokerCase.ExpectedExitCode, err = strconv.Atoi(field("expected exit code")); err != nil {
+		return brokerCase, fmt.Errorf("invalid expected exit code: %s", err)
+	}
+	return brokerCase, nil
+}
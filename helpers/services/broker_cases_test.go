@@ -0,0 +1,75 @@
+package services_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/services"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LoadBrokerCases", func() {
+	var tmpDir string
+
+	BeforeEach(func() {
+		var err error
+		tmpDir, err = ioutil.TempDir("", "broker-cases")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	writeTable := func(contents string) string {
+		path := filepath.Join(tmpDir, "cases.csv")
+		Expect(ioutil.WriteFile(path, []byte(contents), 0644)).To(Succeed())
+		return path
+	}
+
+	It("parses enabled rows and skips commented and blank lines", func() {
+		path := writeTable(`action,sleep seconds,status,body,expected output,expected exit code,output
+provision,0,500,"{""description"": ""some error message""}",some error message,1,XXX
+#provision,0,200,"foo",,,XXX
+
+deprovision,1.5,200,"{}",OK,0,Deleting service
+`)
+
+		cases, err := LoadBrokerCases(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cases).To(Equal([]BrokerCase{
+			{Action: "provision", Status: 500, Body: `{"description": "some error message"}`, ExpectedOutput: "some error message", ExpectedExitCode: 1},
+			{Action: "deprovision", SleepSeconds: 1.5, Status: 200, Body: "{}", ExpectedOutput: "OK", ExpectedExitCode: 0},
+		}))
+
+		Expect(cases[0].Behavior()).To(Equal(map[string]interface{}{
+			"sleep_seconds": 0.0,
+			"status":        500,
+			"raw_body":      `{"description": "some error message"}`,
+		}))
+		Expect(cases[1].String()).To(Equal(`deprovision responding 200 with "{}" after 1.5s`))
+	})
+
+	It("rejects tables without an expected exit code", func() {
+		_, err := LoadBrokerCases(writeTable("action,sleep seconds,status,body,expected output,output\n"))
+		Expect(err).To(MatchError(ContainSubstring(`missing column "expected exit code"`)))
+	})
+
+	It("rejects unknown actions and rows without an expected output", func() {
+		_, err := LoadBrokerCases(writeTable("action,sleep seconds,status,body,expected output,expected exit code\nbind,0,200,{},OK,0\n"))
+		Expect(err).To(MatchError(ContainSubstring(`case 1: unknown action "bind"`)))
+
+		_, err = LoadBrokerCases(writeTable("action,sleep seconds,status,body,expected output,expected exit code\nprovision,0,200,{},,0\n"))
+		Expect(err).To(MatchError(ContainSubstring("expected output must not be empty")))
+	})
+
+	It("loads the acceptance table of the service broker asset", func() {
+		cases, err := LoadBrokerCases(filepath.Join(assetPath(assets.NewAssets().ServiceBroker), "acceptance.csv"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cases).NotTo(BeEmpty())
+	})
+})
@@ -0,0 +1,250 @@
+package services_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/services"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/matchers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type catalog struct {
+	Services []struct {
+		Name  string `json:"name"`
+		ID    string `json:"id"`
+		Plans []Plan `json:"plans"`
+	} `json:"services"`
+}
+
+func brokerRequest(method, url, body string) (int, string) {
+	request, err := http.NewRequest(method, url, strings.NewReader(body))
+	Expect(err).NotTo(HaveOccurred())
+
+	response, err := http.DefaultClient.Do(request)
+	Expect(err).NotTo(HaveOccurred())
+	defer response.Body.Close()
+
+	contents, err := ioutil.ReadAll(response.Body)
+	Expect(err).NotTo(HaveOccurred())
+	return response.StatusCode, string(contents)
+}
+
+var _ = Describe("ServiceBroker", func() {
+	var broker ServiceBroker
+
+	BeforeEach(func() {
+		broker = NewServiceBroker("broker-name", assetPath(assets.NewAssets().GoServiceBroker), nil)
+	})
+
+	Describe("Plans", func() {
+		It("returns the sync plans followed by the async plans", func() {
+			Expect(broker.Plans()).To(Equal(append(append([]Plan{}, broker.SyncPlans...), broker.AsyncPlans...)))
+			Expect(broker.HasPlan(broker.AsyncPlans[1].Name)).To(BeTrue())
+			Expect(broker.HasPlan("not-a-plan")).To(BeFalse())
+		})
+	})
+
+	Describe("ToJSON", func() {
+		It("fills in every placeholder of the template", func() {
+			configJSON := broker.ToJSON()
+
+			Expect(regexp.MustCompile(`<[a-z0-9-]+>`).FindAllString(configJSON, -1)).To(BeEmpty())
+
+			var config map[string]interface{}
+			Expect(json.Unmarshal([]byte(configJSON), &config)).To(Succeed())
+		})
+
+		It("describes the broker's service and plans in the catalog", func() {
+			var config struct {
+				Behaviors struct {
+					Catalog struct {
+						Body catalog `json:"body"`
+					} `json:"catalog"`
+				} `json:"behaviors"`
+			}
+			Expect(json.Unmarshal([]byte(broker.ToJSON()), &config)).To(Succeed())
+
+			services := config.Behaviors.Catalog.Body.Services
+			Expect(services).To(HaveLen(1))
+			Expect(services[0].Name).To(Equal(broker.Service.Name))
+			Expect(services[0].ID).To(Equal(broker.Service.ID))
+			Expect(services[0].Plans).To(ConsistOf(broker.Plans()))
+		})
+	})
+
+	Context("when running locally", func() {
+		var server *httptest.Server
+
+		BeforeEach(func() {
+			server = broker.StartLocal()
+			broker.Configure()
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		lastOperationState := func(instanceID string) string {
+			_, body := brokerRequest("GET", broker.URL+"/v2/service_instances/"+instanceID+"/last_operation", "")
+
+			var lastOperation struct {
+				State string `json:"state"`
+			}
+			Expect(json.Unmarshal([]byte(body), &lastOperation)).To(Succeed())
+			return lastOperation.State
+		}
+
+		It("serves the configured catalog", func() {
+			status, body := brokerRequest("GET", broker.URL+"/v2/catalog", "")
+			Expect(status).To(Equal(http.StatusOK))
+
+			var served catalog
+			Expect(json.Unmarshal([]byte(body), &served)).To(Succeed())
+			Expect(served.Services[0].Name).To(Equal(broker.Service.Name))
+			Expect(served.Services[0].Plans).To(ConsistOf(broker.Plans()))
+		})
+
+		It("overrides the service metadata in the catalog", func() {
+			broker.ConfigureServiceMetadata(map[string]interface{}{"shareable": false})
+
+			_, body := brokerRequest("GET", broker.URL+"/v2/catalog", "")
+
+			var served struct {
+				Services []struct {
+					Metadata map[string]interface{} `json:"metadata"`
+				} `json:"services"`
+			}
+			Expect(json.Unmarshal([]byte(body), &served)).To(Succeed())
+			Expect(served.Services[0].Metadata).To(HaveKeyWithValue("shareable", false))
+			Expect(served.Services[0].Metadata).To(HaveKeyWithValue("displayName", "The Fake Broker"))
+		})
+
+		It("discards behaviors on reset and is configured again", func() {
+			broker.ConfigureBehavior("provision", map[string]interface{}{"sleep_seconds": 0, "status": 500, "body": map[string]interface{}{}})
+			status, _ := brokerRequest("PUT", broker.URL+"/v2/service_instances/instance-guid", `{"plan_id":"`+broker.SyncPlans[0].ID+`"}`)
+			Expect(status).To(Equal(http.StatusInternalServerError))
+
+			broker.Reset()
+
+			status, _ = brokerRequest("PUT", broker.URL+"/v2/service_instances/other-instance-guid", `{"plan_id":"`+broker.SyncPlans[0].ID+`"}`)
+			Expect(status).To(Equal(http.StatusOK))
+
+			_, body := brokerRequest("GET", broker.URL+"/v2/catalog", "")
+			Expect(body).To(ContainSubstring(broker.Service.Name))
+		})
+
+		It("provisions synchronously for sync plans", func() {
+			status, _ := brokerRequest("PUT", broker.URL+"/v2/service_instances/instance-guid", `{"plan_id":"`+broker.SyncPlans[0].ID+`"}`)
+			Expect(status).To(Equal(http.StatusOK))
+		})
+
+		It("provisions asynchronously for async plans and reports progress", func() {
+			status, _ := brokerRequest("PUT", broker.URL+"/v2/service_instances/instance-guid?accepts_incomplete=true", `{"plan_id":"`+broker.AsyncPlans[0].ID+`"}`)
+			Expect(status).To(Equal(http.StatusAccepted))
+
+			Expect(lastOperationState("instance-guid")).To(Equal("in progress"))
+			Expect(lastOperationState("instance-guid")).To(Equal("succeeded"))
+		})
+
+		It("requires accepts_incomplete for async-only behaviors", func() {
+			status, _ := brokerRequest("POST", broker.URL+"/config", `{"behaviors":{"provision":{"default":{"async_only":true,"status":202,"body":{}}}}}`)
+			Expect(status).To(Equal(http.StatusOK))
+
+			status, body := brokerRequest("PUT", broker.URL+"/v2/service_instances/instance-guid", `{"plan_id":"`+broker.AsyncPlans[1].ID+`"}`)
+			Expect(status).To(Equal(422))
+			Expect(body).To(ContainSubstring("AsyncRequired"))
+		})
+
+		It("binds and unbinds", func() {
+			brokerRequest("PUT", broker.URL+"/v2/service_instances/instance-guid", `{"plan_id":"`+broker.SyncPlans[0].ID+`"}`)
+
+			status, body := brokerRequest("PUT", broker.URL+"/v2/service_instances/instance-guid/service_bindings/binding-guid", `{"plan_id":"`+broker.SyncPlans[0].ID+`"}`)
+			Expect(status).To(Equal(http.StatusCreated))
+			Expect(body).To(ContainSubstring("fake-password"))
+
+			status, _ = brokerRequest("DELETE", broker.URL+"/v2/service_instances/instance-guid/service_bindings/binding-guid", "")
+			Expect(status).To(Equal(http.StatusOK))
+		})
+
+		It("binds and unbinds asynchronously for async plans", func() {
+			bindingURL := broker.URL + "/v2/service_instances/instance-guid/service_bindings/binding-guid"
+			bindingState := func() (int, string) {
+				status, body := brokerRequest("GET", bindingURL+"/last_operation", "")
+
+				var lastOperation struct {
+					State string `json:"state"`
+				}
+				json.Unmarshal([]byte(body), &lastOperation)
+				return status, lastOperation.State
+			}
+
+			status, body := brokerRequest("PUT", bindingURL, `{"plan_id":"`+broker.AsyncPlans[0].ID+`"}`)
+			Expect(status).To(Equal(422))
+			Expect(body).To(ContainSubstring("AsyncRequired"))
+
+			status, _ = brokerRequest("PUT", bindingURL+"?accepts_incomplete=true", `{"plan_id":"`+broker.AsyncPlans[0].ID+`"}`)
+			Expect(status).To(Equal(http.StatusAccepted))
+
+			_, state := bindingState()
+			Expect(state).To(Equal("in progress"))
+			_, state = bindingState()
+			Expect(state).To(Equal("succeeded"))
+
+			status, body = brokerRequest("GET", bindingURL, "")
+			Expect(status).To(Equal(http.StatusOK))
+			Expect(body).To(ContainSubstring("fake-password"))
+
+			status, _ = brokerRequest("DELETE", bindingURL+"?accepts_incomplete=true", "")
+			Expect(status).To(Equal(http.StatusAccepted))
+
+			status, _ = brokerRequest("GET", bindingURL, "")
+			Expect(status).To(Equal(http.StatusNotFound))
+
+			_, state = bindingState()
+			Expect(state).To(Equal("in progress"))
+			status, _ = bindingState()
+			Expect(status).To(Equal(http.StatusGone))
+		})
+
+		It("reports its state through /config/all and restores data.json on reset", func() {
+			brokerRequest("PUT", broker.URL+"/v2/service_instances/instance-guid", `{"plan_id":"`+broker.SyncPlans[0].ID+`"}`)
+
+			_, body := brokerRequest("GET", broker.URL+"/config/all", "")
+			Expect(body).To(ContainSubstring("instance-guid"))
+
+			_, body = brokerRequest("GET", broker.URL+"/config", "")
+			Expect(body).NotTo(ContainSubstring("instance-guid"))
+
+			status, _ := brokerRequest("POST", broker.URL+"/config/reset", "")
+			Expect(status).To(Equal(http.StatusOK))
+
+			_, body = brokerRequest("GET", broker.URL+"/v2/catalog", "")
+			Expect(body).To(ContainSubstring(`"fake-service"`))
+			Expect(body).NotTo(ContainSubstring(broker.Service.Name))
+		})
+
+		It("journals the Open Service Broker API requests it receives", func() {
+			brokerRequest("GET", broker.URL+"/v2/catalog", "")
+			brokerRequest("PUT", broker.URL+"/v2/service_instances/instance-guid?accepts_incomplete=true", `{"plan_id":"`+broker.SyncPlans[0].ID+`","parameters":{"size":3}}`)
+			brokerRequest("GET", broker.URL+"/config", "")
+
+			requests := broker.Requests()
+			Expect(requests).To(HaveLen(2))
+			Expect(requests[0].Method).To(Equal("GET"))
+			Expect(requests[0].Path).To(Equal("/v2/catalog"))
+			Expect(requests[1].Query["accepts_incomplete"]).To(ConsistOf("true"))
+			Expect(requests).To(HaveReceivedProvision(broker.SyncPlans[0].ID).WithParameters(map[string]int{"size": 3}))
+
+			broker.ClearRequests()
+			Expect(broker.Requests()).To(BeEmpty())
+		})
+	})
+})
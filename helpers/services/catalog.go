@@ -0,0 +1,124 @@
+package services
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/gomega"
+)
+
+// Catalog is the body of a broker's response to GET /v2/catalog. Empty
+// strings are left out when it is marshalled, so required fields can be
+// removed by clearing them.
+type Catalog struct {
+	Services []CatalogService `json:"services"`
+}
+
+type CatalogService struct {
+	Name        string                 `json:"name,omitempty"`
+	ID          string                 `json:"id,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Bindable    bool                   `json:"bindable"`
+	Tags        []string               `json:"tags,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+	Plans       []CatalogPlan          `json:"plans"`
+}
+
+type CatalogPlan struct {
+	Name        string `json:"name,omitempty"`
+	ID          string `json:"id,omitempty"`
+	Description string `json:"description,omitempty"`
+	// Bindable overrides the bindable field of the service when it is set.
+	Bindable *bool                  `json:"bindable,omitempty"`
+	Free     *bool                  `json:"free,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+	Schemas  map[string]interface{} `json:"schemas,omitempty"`
+}
+
+// Catalog returns a valid catalog with the broker's service and its sync and
+// async plans, for tests to modify before passing it to ConfigureCatalog.
+func (b ServiceBroker) Catalog() Catalog {
+	plans := []CatalogPlan{}
+	for _, plan := range b.Plans() {
+		plans = append(plans, CatalogPlan{
+			Name:        plan.Name,
+			ID:          plan.ID,
+			Description: "fake plan",
+		})
+	}
+
+	return Catalog{
+		Services: []CatalogService{{
+			Name:        b.Service.Name,
+			ID:          b.Service.ID,
+			Description: "fake service",
+			Bindable:    true,
+			Plans:       plans,
+		}},
+	}
+}
+
+// ConfigureCatalog makes the broker respond to GET /v2/catalog with catalog.
+// The catalog is not validated, so that tests can register invalid ones.
+func (b ServiceBroker) ConfigureCatalog(catalog Catalog) {
+	configJSON, err := json.Marshal(map[string]interface{}{
+		"behaviors": map[string]interface{}{
+			"catalog": map[string]interface{}{
+				"sleep_seconds": 0,
+				"status":        200,
+				"body":          catalog,
+			},
+		},
+	})
+	Expect(err).NotTo(HaveOccurred())
+	b.configureWith(string(configJSON))
+}
+
+// PlanSchemas are the JSON schemas that a plan's configuration parameters
+// must conform to. Schemas that are nil are left out of the catalog.
+type PlanSchemas struct {
+	ServiceInstanceCreate map[string]interface{}
+	ServiceInstanceUpdate map[string]interface{}
+	ServiceBindingCreate  map[string]interface{}
+}
+
+// Catalog returns the schemas in the form of the schemas field of a catalog
+// plan.
+func (s PlanSchemas) Catalog() map[string]interface{} {
+	parameters := func(schema map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"parameters": schema}
+	}
+
+	serviceInstance := map[string]interface{}{}
+	if s.ServiceInstanceCreate != nil {
+		serviceInstance["create"] = parameters(s.ServiceInstanceCreate)
+	}
+	if s.ServiceInstanceUpdate != nil {
+		serviceInstance["update"] = parameters(s.ServiceInstanceUpdate)
+	}
+
+	schemas := map[string]interface{}{}
+	if len(serviceInstance) > 0 {
+		schemas["service_instance"] = serviceInstance
+	}
+	if s.ServiceBindingCreate != nil {
+		schemas["service_binding"] = map[string]interface{}{
+			"create": parameters(s.ServiceBindingCreate),
+		}
+	}
+	return schemas
+}
+
+// ObjectSchema returns a JSON Schema draft 4 schema for an object with the
+// given properties, which allows no other properties.
+func ObjectSchema(properties map[string]interface{}, required ...string) map[string]interface{} {
+	schema := map[string]interface{}{
+		"$schema":              "http://json-schema.org/draft-04/schema#",
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
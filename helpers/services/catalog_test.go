@@ -0,0 +1,121 @@
+package services_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/services"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Catalog", func() {
+	var (
+		broker ServiceBroker
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		broker = NewServiceBroker("broker-name", assetPath(assets.NewAssets().GoServiceBroker), nil)
+		server = broker.StartLocal()
+		broker.Configure()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	servedCatalog := func() map[string]interface{} {
+		status, body := brokerRequest("GET", broker.URL+"/v2/catalog", "")
+		Expect(status).To(Equal(http.StatusOK))
+
+		var served map[string]interface{}
+		Expect(json.Unmarshal([]byte(body), &served)).To(Succeed())
+		return served
+	}
+
+	It("describes the broker's service and all of its plans", func() {
+		catalog := broker.Catalog()
+
+		Expect(catalog.Services).To(HaveLen(1))
+		Expect(catalog.Services[0].Name).To(Equal(broker.Service.Name))
+		Expect(catalog.Services[0].ID).To(Equal(broker.Service.ID))
+
+		plans := []Plan{}
+		for _, plan := range catalog.Services[0].Plans {
+			plans = append(plans, Plan{Name: plan.Name, ID: plan.ID})
+		}
+		Expect(plans).To(Equal(broker.Plans()))
+	})
+
+	It("makes the broker serve the configured catalog", func() {
+		catalog := broker.Catalog()
+		catalog.Services[0].Plans[1].ID = catalog.Services[0].Plans[0].ID
+		broker.ConfigureCatalog(catalog)
+
+		expected, err := json.Marshal(catalog)
+		Expect(err).NotTo(HaveOccurred())
+		served, err := json.Marshal(servedCatalog())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(served).To(MatchJSON(expected))
+	})
+
+	It("leaves out fields that have been cleared", func() {
+		catalog := broker.Catalog()
+		catalog.Services[0].Name = ""
+		catalog.Services[0].Plans[0].Description = ""
+		broker.ConfigureCatalog(catalog)
+
+		service := servedCatalog()["services"].([]interface{})[0].(map[string]interface{})
+		Expect(service).NotTo(HaveKey("name"))
+		Expect(service).To(HaveKeyWithValue("bindable", true))
+
+		plan := service["plans"].([]interface{})[0].(map[string]interface{})
+		Expect(plan).NotTo(HaveKey("description"))
+		Expect(plan).NotTo(HaveKey("bindable"))
+	})
+
+	Describe("PlanSchemas", func() {
+		sizeSchema := ObjectSchema(map[string]interface{}{
+			"size": map[string]interface{}{"type": "string"},
+		}, "size")
+
+		It("places each schema under its resource and action", func() {
+			schemas := PlanSchemas{
+				ServiceInstanceCreate: sizeSchema,
+				ServiceBindingCreate:  sizeSchema,
+			}.Catalog()
+
+			Expect(schemas).To(Equal(map[string]interface{}{
+				"service_instance": map[string]interface{}{
+					"create": map[string]interface{}{"parameters": sizeSchema},
+				},
+				"service_binding": map[string]interface{}{
+					"create": map[string]interface{}{"parameters": sizeSchema},
+				},
+			}))
+		})
+
+		It("makes the broker serve the schemas with its plan", func() {
+			catalog := broker.Catalog()
+			catalog.Services[0].Plans[0].Schemas = PlanSchemas{ServiceInstanceCreate: sizeSchema}.Catalog()
+			broker.ConfigureCatalog(catalog)
+
+			plan := servedCatalog()["services"].([]interface{})[0].(map[string]interface{})["plans"].([]interface{})[0].(map[string]interface{})
+			Expect(plan).To(HaveKeyWithValue("schemas", map[string]interface{}{
+				"service_instance": map[string]interface{}{
+					"create": map[string]interface{}{"parameters": map[string]interface{}{
+						"$schema":              "http://json-schema.org/draft-04/schema#",
+						"type":                 "object",
+						"properties":           map[string]interface{}{"size": map[string]interface{}{"type": "string"}},
+						"required":             []interface{}{"size"},
+						"additionalProperties": false,
+					}},
+				},
+			}))
+		})
+	})
+})
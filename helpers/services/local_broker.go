@@ -0,0 +1,28 @@
+package services
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/assets/go-service-broker/broker"
+)
+
+// StartLocal serves the Go service broker in-process and points b at it, so
+// the broker can be configured and exercised without a Cloud Foundry
+// deployment. b.Path must be the Go service broker asset. Callers are
+// responsible for closing the returned server.
+func (b *ServiceBroker) StartLocal() *httptest.Server {
+	data, err := ioutil.ReadFile(filepath.Join(b.Path, "data.json"))
+	Expect(err).NotTo(HaveOccurred())
+
+	serviceBroker, err := broker.New(data, GinkgoWriter)
+	Expect(err).NotTo(HaveOccurred())
+
+	server := httptest.NewServer(serviceBroker)
+	b.URL = server.URL
+	return server
+}
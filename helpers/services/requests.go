@@ -0,0 +1,48 @@
+package services
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+
+	"github.com/cloudfoundry/cf-acceptance-tests/assets/go-service-broker/broker"
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+)
+
+// Requests returns the journal of Open Service Broker API requests the broker
+// has received, oldest first. Only the Go broker keeps a journal.
+func (b ServiceBroker) Requests() []broker.Request {
+	var requests []broker.Request
+	Expect(json.Unmarshal(b.journal("GET"), &requests)).To(Succeed())
+	return requests
+}
+
+// ClearRequests empties the broker's request journal.
+func (b ServiceBroker) ClearRequests() {
+	b.journal("DELETE")
+}
+
+func (b ServiceBroker) journal(method string) []byte {
+	if b.URL != "" {
+		request, err := http.NewRequest(method, b.URL+"/requests", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		response, err := http.DefaultClient.Do(request)
+		Expect(err).NotTo(HaveOccurred())
+		defer response.Body.Close()
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+
+		body, err := ioutil.ReadAll(response.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return body
+	}
+
+	curl := helpers.Curl(Config, "-X", method, helpers.AppUri(b.Name, "/requests", Config)).Wait(Config.DefaultTimeoutDuration())
+	Expect(curl).To(Exit(0))
+	return curl.Out.Contents()
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
 	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
 )
 
 type ProcessList struct {
@@ -13,10 +15,28 @@ type ProcessList struct {
 }
 
 type Process struct {
-	Guid    string `json:"guid"`
-	Type    string `json:"type"`
-	Command string `json:"command"`
-	Name    string `json:"-"`
+	Guid        string             `json:"guid"`
+	Type        string             `json:"type"`
+	Command     string             `json:"command"`
+	Instances   int                `json:"instances"`
+	MemoryInMB  int                `json:"memory_in_mb"`
+	DiskInMB    int                `json:"disk_in_mb"`
+	HealthCheck ProcessHealthCheck `json:"health_check"`
+	Name        string             `json:"-"`
+}
+
+type ProcessHealthCheck struct {
+	Type string `json:"type"`
+}
+
+type ProcessInstanceList struct {
+	Instances []ProcessInstance `json:"resources"`
+}
+
+type ProcessInstance struct {
+	Index  int    `json:"index"`
+	State  string `json:"state"`
+	Uptime int    `json:"uptime"`
 }
 
 func GetProcesses(appGuid, appName string) []Process {
@@ -42,3 +62,32 @@ func GetProcessByType(processes []Process, processType string) Process {
 	}
 	return Process{}
 }
+
+func GetProcessInstances(appGuid, processType string) []ProcessInstance {
+	statsURL := fmt.Sprintf("/v3/apps/%s/processes/%s/stats", appGuid, processType)
+	session := cf.Cf("curl", statsURL)
+	bytes := session.Wait(Config.DefaultTimeoutDuration()).Out.Contents()
+
+	instances := ProcessInstanceList{}
+	json.Unmarshal(bytes, &instances)
+
+	return instances.Instances
+}
+
+func ScaleProcessInstances(appGuid, processType string, instances int) {
+	scalePath := fmt.Sprintf("/v3/apps/%s/processes/%s/scale", appGuid, processType)
+	scaleBody := fmt.Sprintf(`{"instances":%d}`, instances)
+	Expect(cf.Cf("curl", scalePath, "-X", "PUT", "-d", scaleBody).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+}
+
+func ScaleProcessDisk(appGuid, processType, diskInMb string) {
+	scalePath := fmt.Sprintf("/v3/apps/%s/processes/%s/scale", appGuid, processType)
+	scaleBody := fmt.Sprintf(`{"disk_in_mb":"%s"}`, diskInMb)
+	Expect(cf.Cf("curl", scalePath, "-X", "PUT", "-d", scaleBody).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+}
+
+func UpdateProcessHealthCheck(processGuid, healthCheckType string) {
+	processURL := fmt.Sprintf("/v3/processes/%s", processGuid)
+	processBody := fmt.Sprintf(`{"health_check":{"type":"%s"}}`, healthCheckType)
+	Expect(cf.Cf("curl", processURL, "-X", "PATCH", "-d", processBody).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+}
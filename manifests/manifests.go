@@ -0,0 +1,185 @@
+package manifests
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/app_helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/manifest"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/skip_messages"
+)
+
+func assetPath(asset string) string {
+	path, err := filepath.Abs(asset)
+	Expect(err).NotTo(HaveOccurred())
+	return path
+}
+
+func pushManifest(manifestPath string, appNames ...string) {
+	Expect(cf.Cf("push", "-f", manifestPath, "--no-start").Wait(Config.CfPushTimeoutDuration())).To(Exit(0))
+
+	for _, appName := range appNames {
+		app_helpers.SetBackend(appName)
+		Expect(cf.Cf("start", appName).Wait(Config.CfPushTimeoutDuration())).To(Exit(0))
+	}
+}
+
+func generatedManifest(appName, dir string) manifest.Application {
+	manifestPath := filepath.Join(dir, appName+"-generated.yml")
+	Expect(cf.Cf("create-app-manifest", appName, "-p", manifestPath).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+
+	generated, err := manifest.ParseFile(manifestPath)
+	Expect(err).NotTo(HaveOccurred())
+
+	app, ok := generated.Application(appName)
+	Expect(ok).To(BeTrue(), "generated manifest does not contain "+appName)
+	return app
+}
+
+var _ = ManifestsDescribe("Application manifests", func() {
+	var tmpDir string
+
+	BeforeEach(func() {
+		if Config.GetBackend() != "diego" {
+			Skip(skip_messages.SkipDiegoMessage)
+		}
+
+		var err error
+		tmpDir, err = ioutil.TempDir("", "cats-manifests")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmpDir)
+	})
+
+	Describe("a multi-app manifest", func() {
+		var (
+			webAppName    string
+			workerAppName string
+			serviceName   string
+			pushed        manifest.Manifest
+		)
+
+		BeforeEach(func() {
+			webAppName = random_name.CATSRandomName("APP")
+			workerAppName = random_name.CATSRandomName("APP")
+			serviceName = random_name.CATSRandomName("SVIN")
+
+			Expect(cf.Cf("create-user-provided-service", serviceName, "-p", `{"username":"admin","password":"my-service"}`).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+
+			pushed = manifest.New(
+				manifest.NewApplication(webAppName).
+					WithPath(assetPath(assets.NewAssets().Dora)).
+					WithBuildpack(Config.GetRubyBuildpackName()).
+					WithMemory(DEFAULT_MEMORY_LIMIT).
+					WithInstances(2).
+					WithHTTPHealthCheck("/health").
+					WithEnv("MANIFEST_ENV", "from-the-manifest").
+					WithRoute(strings.ToLower(webAppName)+"."+Config.GetAppsDomain()).
+					WithService(serviceName),
+				manifest.NewApplication(workerAppName).
+					WithPath(assetPath(assets.NewAssets().WorkerApp)).
+					WithBuildpack(Config.GetGoBuildpackName()).
+					WithMemory(DEFAULT_MEMORY_LIMIT).
+					WithHealthCheck("process").
+					WithNoRoute(),
+			)
+
+			manifestPath, err := pushed.WriteTo(tmpDir, "manifest.yml")
+			Expect(err).NotTo(HaveOccurred())
+
+			pushManifest(manifestPath, webAppName, workerAppName)
+		})
+
+		AfterEach(func() {
+			app_helpers.AppReport(webAppName, Config.DefaultTimeoutDuration())
+			app_helpers.AppReport(workerAppName, Config.DefaultTimeoutDuration())
+
+			Expect(cf.Cf("delete", webAppName, "-f", "-r").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			Expect(cf.Cf("delete", workerAppName, "-f", "-r").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			Expect(cf.Cf("delete-service", serviceName, "-f").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		})
+
+		It("applies every attribute from the manifest", func() {
+			Eventually(func() string {
+				return helpers.CurlApp(Config, webAppName, "/env/MANIFEST_ENV")
+			}, Config.DefaultTimeoutDuration()).Should(Equal("from-the-manifest"))
+
+			Expect(helpers.CurlApp(Config, webAppName, "/env/VCAP_SERVICES")).To(ContainSubstring(serviceName))
+
+			Eventually(func() *Session {
+				logs := cf.Cf("logs", "--recent", workerAppName)
+				Expect(logs.Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+				return logs
+			}, Config.DefaultTimeoutDuration()).Should(Say("I am working at"))
+		})
+
+		It("round-trips through create-app-manifest", func() {
+			for _, app := range pushed.Applications {
+				Expect(app.Contains(generatedManifest(app.Name, tmpDir))).To(Succeed())
+			}
+		})
+	})
+
+	Describe("manifest inheritance", func() {
+		var (
+			appName string
+			base    manifest.Manifest
+			child   manifest.Manifest
+		)
+
+		BeforeEach(func() {
+			appName = random_name.CATSRandomName("APP")
+
+			base = manifest.Manifest{}.
+				WithBuildpack(Config.GetRubyBuildpackName()).
+				WithMemory(DEFAULT_MEMORY_LIMIT).
+				WithEnv("INHERITED_ENV", "from-the-parent")
+			basePath, err := base.WriteTo(tmpDir, "base.yml")
+			Expect(err).NotTo(HaveOccurred())
+
+			child = manifest.New(
+				manifest.NewApplication(appName).
+					WithPath(assetPath(assets.NewAssets().Dora)).
+					WithRoute(strings.ToLower(appName)+"."+Config.GetAppsDomain()).
+					WithEnv("CHILD_ENV", "from-the-child"),
+			).InheritingFrom(basePath)
+			childPath, err := child.WriteTo(tmpDir, "manifest.yml")
+			Expect(err).NotTo(HaveOccurred())
+
+			pushManifest(childPath, appName)
+		})
+
+		AfterEach(func() {
+			app_helpers.AppReport(appName, Config.DefaultTimeoutDuration())
+			Expect(cf.Cf("delete", appName, "-f", "-r").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		})
+
+		It("merges attributes from the parent manifest", func() {
+			Eventually(func() string {
+				return helpers.CurlApp(Config, appName, "/env/INHERITED_ENV")
+			}, Config.DefaultTimeoutDuration()).Should(Equal("from-the-parent"))
+			Expect(helpers.CurlApp(Config, appName, "/env/CHILD_ENV")).To(Equal("from-the-child"))
+
+			expected := child.Applications[0].
+				WithBuildpack(base.Buildpack).
+				WithMemory(base.Memory).
+				WithEnv("INHERITED_ENV", "from-the-parent")
+			Expect(expected.Contains(generatedManifest(appName, tmpDir))).To(Succeed())
+		})
+	})
+})
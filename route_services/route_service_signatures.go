@@ -0,0 +1,188 @@
+package route_services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+
+	. "code.cloudfoundry.org/cf-routing-test-helpers/helpers"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/skip_messages"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+)
+
+// routeServiceModeHeader tells assets/go-route-service how to handle a request.
+const routeServiceModeHeader = "X-Cats-Route-Service-Mode"
+
+type routeServiceHeaders struct {
+	ForwardedUrl   string `json:"X-CF-Forwarded-Url"`
+	ProxySignature string `json:"X-CF-Proxy-Signature"`
+	ProxyMetadata  string `json:"X-CF-Proxy-Metadata"`
+}
+
+// curlAppInMode requests the root of an app with the given route service
+// mode, and returns the response body and status code.
+func curlAppInMode(appName, mode string) (string, int) {
+	curl := helpers.Curl(Config, helpers.AppUri(appName, "/", Config),
+		"-s",
+		"-H", routeServiceModeHeader+": "+mode,
+		"-w", "\n%{http_code}",
+	).Wait(Config.DefaultTimeoutDuration())
+	Expect(curl).To(Exit(0))
+
+	output := strings.TrimSpace(string(curl.Out.Contents()))
+	separator := strings.LastIndex(output, "\n")
+	status, err := strconv.Atoi(output[separator+1:])
+	Expect(err).NotTo(HaveOccurred())
+
+	if separator < 0 {
+		return "", status
+	}
+	return output[:separator], status
+}
+
+var _ = RouteServicesDescribe("Route service signatures", func() {
+	var (
+		serviceInstanceName string
+		brokerName          string
+		appName             string
+		routeServiceName    string
+		bound               bool
+	)
+
+	BeforeEach(func() {
+		if Config.GetBackend() != "diego" {
+			Skip(skip_messages.SkipDiegoMessage)
+		}
+
+		routeServiceName = random_name.CATSRandomName("APP")
+		brokerName = random_name.CATSRandomName("BRKR")
+		serviceInstanceName = random_name.CATSRandomName("SVIN")
+		appName = random_name.CATSRandomName("APP")
+
+		serviceName := random_name.CATSRandomName("SVC")
+		brokerAppName := random_name.CATSRandomName("APP")
+
+		createServiceBroker(brokerName, brokerAppName, serviceName)
+		createServiceInstance(serviceInstanceName, serviceName)
+
+		PushAppNoStart(appName, assets.NewAssets().Golang, Config.GetGoBuildpackName(), Config.GetAppsDomain(), Config.CfPushTimeoutDuration(), DEFAULT_MEMORY_LIMIT)
+		EnableDiego(appName, Config.DefaultTimeoutDuration())
+		StartApp(appName, Config.CfPushTimeoutDuration())
+
+		PushAppNoStart(routeServiceName, assets.NewAssets().GoRouteService, Config.GetGoBuildpackName(), Config.GetAppsDomain(), Config.CfPushTimeoutDuration(), DEFAULT_MEMORY_LIMIT)
+		Expect(cf.Cf("set-env", routeServiceName, "SKIP_SSL_VALIDATION", strconv.FormatBool(Config.GetSkipSSLValidation())).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		StartApp(routeServiceName, Config.CfPushTimeoutDuration())
+		configureBroker(brokerAppName, routeServiceName)
+
+		bindRouteToService(appName, serviceInstanceName)
+		bound = true
+	})
+
+	AfterEach(func() {
+		AppReport(appName, Config.DefaultTimeoutDuration())
+		AppReport(routeServiceName, Config.DefaultTimeoutDuration())
+
+		if bound {
+			unbindRouteFromService(appName, serviceInstanceName)
+		}
+		deleteServiceInstance(serviceInstanceName)
+		deleteServiceBroker(brokerName)
+		DeleteApp(appName, Config.DefaultTimeoutDuration())
+		DeleteApp(routeServiceName, Config.DefaultTimeoutDuration())
+	})
+
+	It("sends the route service the forwarded url, a signature and its metadata", func() {
+		var body string
+		Eventually(func() int {
+			var status int
+			body, status = curlAppInMode(appName, "echo")
+			return status
+		}, Config.DefaultTimeoutDuration()).Should(Equal(http.StatusOK))
+
+		var headers routeServiceHeaders
+		Expect(json.Unmarshal([]byte(body), &headers)).To(Succeed())
+
+		appUrl, err := url.Parse(helpers.AppUri(appName, "/", Config))
+		Expect(err).NotTo(HaveOccurred())
+		forwardedUrl, err := url.Parse(headers.ForwardedUrl)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(forwardedUrl.Host).To(Equal(appUrl.Host))
+		Expect(forwardedUrl.Path).To(Equal(appUrl.Path))
+
+		signature, err := base64.URLEncoding.DecodeString(headers.ProxySignature)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(signature).NotTo(BeEmpty())
+
+		metadata, err := base64.URLEncoding.DecodeString(headers.ProxyMetadata)
+		Expect(err).NotTo(HaveOccurred())
+		var decodedMetadata map[string]interface{}
+		Expect(json.Unmarshal(metadata, &decodedMetadata)).To(Succeed())
+		Expect(decodedMetadata).To(HaveKey("nonce"))
+	})
+
+	It("routes to the app when the route service returns the signature unchanged", func() {
+		Eventually(func() string {
+			body, _ := curlAppInMode(appName, "forward")
+			return body
+		}, Config.DefaultTimeoutDuration()).Should(ContainSubstring("go, world"))
+	})
+
+	It("rejects a tampered signature", func() {
+		Eventually(func() int {
+			_, status := curlAppInMode(appName, "tamper")
+			return status
+		}, Config.DefaultTimeoutDuration()).Should(Equal(http.StatusBadRequest))
+	})
+
+	It("rejects an expired signature", func() {
+		Eventually(func() string {
+			body, _ := curlAppInMode(appName, "forward")
+			return body
+		}, Config.DefaultTimeoutDuration()).Should(ContainSubstring("go, world"))
+
+		time.Sleep(Config.RouteServiceSignatureTimeoutDuration() + 5*time.Second)
+
+		body, status := curlAppInMode(appName, "replay")
+		Expect(status).To(Equal(http.StatusBadRequest))
+		Expect(body).NotTo(ContainSubstring("go, world"))
+	})
+
+	It("propagates server errors from the route service to the client", func() {
+		Eventually(func() int {
+			_, status := curlAppInMode(appName, "fail")
+			return status
+		}, Config.DefaultTimeoutDuration()).Should(Equal(http.StatusServiceUnavailable))
+
+		body, _ := curlAppInMode(appName, "fail")
+		Expect(body).To(ContainSubstring("route service unavailable"))
+	})
+
+	It("routes directly to the app once the route is unbound", func() {
+		Eventually(func() int {
+			_, status := curlAppInMode(appName, "fail")
+			return status
+		}, Config.DefaultTimeoutDuration()).Should(Equal(http.StatusServiceUnavailable))
+
+		unbindRouteFromService(appName, serviceInstanceName)
+		bound = false
+
+		Eventually(func() string {
+			body, _ := curlAppInMode(appName, "fail")
+			return body
+		}, Config.DefaultTimeoutDuration()).Should(ContainSubstring("go, world"))
+		body, _ := curlAppInMode(appName, "echo")
+		Expect(body).NotTo(ContainSubstring("X-CF-Forwarded-Url"))
+	})
+})
@@ -0,0 +1,149 @@
+package routing
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+
+	. "code.cloudfoundry.org/cf-routing-test-helpers/helpers"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/route_distribution"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/router_response"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = RoutingDescribe("Backend failures", func() {
+	var appName string
+
+	misbehave := func(mode string, args ...string) router_response.Response {
+		return router_response.Curl(appName, "/misbehave?index=0&mode="+mode, Config.DefaultTimeoutDuration(), args...)
+	}
+
+	AfterEach(func() {
+		AppReport(appName, Config.DefaultTimeoutDuration())
+		DeleteApp(appName, Config.DefaultTimeoutDuration())
+	})
+
+	Context("with two instances", func() {
+		BeforeEach(func() {
+			appName = random_name.CATSRandomName("APP")
+			PushApp(appName, assets.NewAssets().GoUnreliable, Config.GetGoBuildpackName(), Config.GetAppsDomain(), Config.CfPushTimeoutDuration(), DEFAULT_MEMORY_LIMIT)
+			ScaleAppInstances(appName, 2, Config.CfPushTimeoutDuration())
+
+			seen := map[string]bool{}
+			Eventually(func() map[string]bool {
+				seen[strings.TrimSpace(helpers.CurlAppRoot(Config, appName))] = true
+				return seen
+			}, Config.DefaultTimeoutDuration()).Should(And(HaveKey("instance 0"), HaveKey("instance 1")))
+		})
+
+		Context("when an instance closes the connection without responding", func() {
+			It("retries idempotent requests on another instance", func() {
+				for i := 0; i < 10; i++ {
+					response := misbehave("close")
+					Expect(response.Status()).To(Equal(200))
+					Expect(response.Body).To(ContainSubstring("instance 1"))
+				}
+			})
+
+			It("responds to non-idempotent requests with 502 and endpoint_failure", func() {
+				Eventually(func() string {
+					return misbehave("close", "-X", "POST", "-d", "").String()
+				}, Config.DefaultTimeoutDuration()).Should(Equal("502 endpoint_failure"))
+			})
+		})
+
+		Context("when an instance responds with 502", func() {
+			It("passes the response through without a router error", func() {
+				var response router_response.Response
+				Eventually(func() int {
+					response = misbehave("502")
+					return response.Status()
+				}, Config.DefaultTimeoutDuration()).Should(Equal(502))
+
+				Expect(response.RouterError).To(BeEmpty())
+				Expect(response.Body).To(ContainSubstring("bad gateway from instance 0"))
+			})
+		})
+
+		Context("when an instance does not respond within the request timeout of the router", func() {
+			BeforeEach(func() {
+				if Config.RouterRequestTimeoutDuration() == 0 {
+					Skip(`Skipping this test because Config.RouterRequestTimeout is not set.`)
+				}
+			})
+
+			It("responds with 504 and endpoint_failure", func() {
+				requestTimeout := Config.RouterRequestTimeoutDuration() + time.Minute
+				hang := fmt.Sprintf("/misbehave?index=0&mode=hang&seconds=%d", int(requestTimeout.Seconds()))
+
+				Eventually(func() string {
+					return router_response.Curl(appName, hang, requestTimeout).String()
+				}, 3*requestTimeout).Should(Equal("504 endpoint_failure"))
+			})
+		})
+
+		Context("when an instance dies", func() {
+			// outcomes sends POST requests, which the router does not retry,
+			// and counts the instance that served each of them, or the
+			// status and router error of those that failed.
+			outcomes := func(n int) Histogram {
+				return Sample(n, func() string {
+					response := router_response.Curl(appName, "/", Config.DefaultTimeoutDuration(), "-X", "POST", "-d", "")
+					if response.Status() == 200 {
+						return strings.TrimSpace(response.Body)
+					}
+					return response.String()
+				}, func(outcome string) string { return outcome })
+			}
+
+			instances := []string{"instance 0", "instance 1"}
+			unexpected := func(histogram Histogram) []string { return histogram.Unexpected(instances) }
+
+			It("prunes the instance within the prune timeout of the router", func() {
+				// The request that kills the instance fails through the router.
+				Eventually(func() string {
+					return misbehave("exit", "-X", "POST", "-d", "").String()
+				}, Config.DefaultTimeoutDuration()).Should(Equal("502 endpoint_failure"))
+
+				// Diego restarts the instance, so instance 0 answers again once
+				// it is back; until it is pruned the dead one fails requests.
+				Eventually(func() Histogram {
+					return outcomes(10)
+				}, Config.RouterPruneTimeoutDuration(), time.Second).Should(WithTransform(unexpected, BeEmpty()))
+
+				Consistently(func() Histogram {
+					return outcomes(10)
+				}, Config.DefaultTimeoutDuration(), time.Second).Should(WithTransform(unexpected, BeEmpty()))
+			})
+		})
+	})
+
+	Context("when the only instance of a route refuses connections", func() {
+		BeforeEach(func() {
+			appName = random_name.CATSRandomName("APP")
+
+			// The process health check keeps Diego from restarting the
+			// instance, so its route stays registered without a backend.
+			PushAppNoStart(appName, assets.NewAssets().GoUnreliable, Config.GetGoBuildpackName(), Config.GetAppsDomain(), Config.CfPushTimeoutDuration(), DEFAULT_MEMORY_LIMIT, "-u", "process")
+			SetBackend(appName, Config.DefaultTimeoutDuration())
+			StartApp(appName, Config.CfPushTimeoutDuration())
+		})
+
+		// The backend stays registered, so the router reports the failed
+		// connections rather than no_endpoints, which is for routes without
+		// backends.
+		It("responds with 502 and endpoint_failure", func() {
+			Expect(misbehave("unlisten").Body).To(ContainSubstring("instance 0 stopped listening"))
+
+			Eventually(func() string {
+				return router_response.Curl(appName, "/", Config.DefaultTimeoutDuration()).String()
+			}, Config.DefaultTimeoutDuration()).Should(Equal("502 endpoint_failure"))
+		})
+	})
+})
@@ -0,0 +1,147 @@
+package routing
+
+import (
+	"strings"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+
+	. "code.cloudfoundry.org/cf-routing-test-helpers/helpers"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/route_distribution"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+	"github.com/onsi/gomega/types"
+)
+
+const distributionSampleSize = 200
+
+var _ = RoutingDescribe("Route distribution", func() {
+	var helloRoutingAsset = assets.NewAssets().HelloRouting
+
+	// sampleRoute sends requests to the route of hostname and counts the
+	// instances of the hello-routing apps that served them. Failed requests
+	// are counted under the empty string.
+	sampleRoute := func(hostname string, n int) Histogram {
+		uri := helpers.AppUri(hostname, "/", Config)
+		return Sample(n, func() string {
+			return string(helpers.Curl(Config, uri).Wait(Config.DefaultTimeoutDuration()).Out.Contents())
+		}, HelloRoutingBackend)
+	}
+
+	// waitForBackends waits until every backend has served a request through
+	// the route.
+	waitForBackends := func(hostname string, backends []string) {
+		served := []types.GomegaMatcher{}
+		for _, backend := range backends {
+			served = append(served, HaveKey(backend))
+		}
+
+		seen := Histogram{}
+		Eventually(func() Histogram {
+			for backend, count := range sampleRoute(hostname, len(backends)) {
+				seen[backend] += count
+			}
+			return seen
+		}, Config.DefaultTimeoutDuration()).Should(And(served...))
+	}
+
+	Context("when multiple apps are mapped to the same route", func() {
+		var (
+			app1     string
+			app2     string
+			hostname string
+		)
+
+		BeforeEach(func() {
+			app1 = random_name.CATSRandomName("APP")
+			PushApp(app1, helloRoutingAsset, Config.GetRubyBuildpackName(), Config.GetAppsDomain(), Config.CfPushTimeoutDuration(), DEFAULT_MEMORY_LIMIT)
+			app2 = random_name.CATSRandomName("APP")
+			PushApp(app2, helloRoutingAsset, Config.GetRubyBuildpackName(), Config.GetAppsDomain(), Config.CfPushTimeoutDuration(), DEFAULT_MEMORY_LIMIT)
+
+			ScaleAppInstances(app1, 2, Config.CfPushTimeoutDuration())
+			ScaleAppInstances(app2, 2, Config.CfPushTimeoutDuration())
+
+			hostname = strings.ToLower(random_name.CATSRandomName("ROUTE"))
+			for _, app := range []string{app1, app2} {
+				Expect(cf.Cf("map-route", app, Config.GetAppsDomain(), "--hostname", hostname).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			}
+		})
+
+		AfterEach(func() {
+			AppReport(app1, Config.DefaultTimeoutDuration())
+			AppReport(app2, Config.DefaultTimeoutDuration())
+			DeleteApp(app1, Config.DefaultTimeoutDuration())
+			DeleteApp(app2, Config.DefaultTimeoutDuration())
+		})
+
+		It("distributes requests evenly across the instances of all apps", func() {
+			backends := append(Backends(app1, 2), Backends(app2, 2)...)
+			waitForBackends(hostname, backends)
+
+			Expect(sampleRoute(hostname, distributionSampleSize)).To(BeEvenlyDistributedAcross(backends...))
+		})
+	})
+
+	Context("when instances are added or removed mid-run", func() {
+		var appName string
+
+		// sampleWhileScaling samples the route of the app while it is scaled
+		// to the given number of instances.
+		sampleWhileScaling := func(instances int) Histogram {
+			scaled := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(scaled)
+				ScaleAppInstances(appName, instances, Config.CfPushTimeoutDuration())
+			}()
+
+			histogram := Histogram{}
+			for {
+				select {
+				case <-scaled:
+					return histogram
+				default:
+					for backend, count := range sampleRoute(appName, 10) {
+						histogram[backend] += count
+					}
+				}
+			}
+		}
+
+		BeforeEach(func() {
+			appName = random_name.CATSRandomName("APP")
+			PushApp(appName, helloRoutingAsset, Config.GetRubyBuildpackName(), Config.GetAppsDomain(), Config.CfPushTimeoutDuration(), DEFAULT_MEMORY_LIMIT)
+			ScaleAppInstances(appName, 2, Config.CfPushTimeoutDuration())
+			waitForBackends(appName, Backends(appName, 2))
+		})
+
+		AfterEach(func() {
+			AppReport(appName, Config.DefaultTimeoutDuration())
+			DeleteApp(appName, Config.DefaultTimeoutDuration())
+		})
+
+		It("adds new instances to the rotation without dropping requests", func() {
+			Expect(sampleRoute(appName, distributionSampleSize)).To(BeEvenlyDistributedAcross(Backends(appName, 2)...))
+
+			Expect(sampleWhileScaling(3).Unexpected(Backends(appName, 3))).To(BeEmpty())
+
+			waitForBackends(appName, Backends(appName, 3))
+			Expect(sampleRoute(appName, distributionSampleSize)).To(BeEvenlyDistributedAcross(Backends(appName, 3)...))
+		})
+
+		It("removes stopped instances from the rotation without dropping requests", func() {
+			ScaleAppInstances(appName, 3, Config.CfPushTimeoutDuration())
+			waitForBackends(appName, Backends(appName, 3))
+
+			Expect(sampleWhileScaling(2).Unexpected(Backends(appName, 3))).To(BeEmpty())
+
+			Eventually(func() Histogram {
+				return sampleRoute(appName, distributionSampleSize)
+			}, Config.DefaultTimeoutDuration()).Should(BeEvenlyDistributedAcross(Backends(appName, 2)...))
+		})
+	})
+})
@@ -0,0 +1,135 @@
+package routing
+
+import (
+	"crypto/tls"
+	"net/http"
+	"strings"
+	"time"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+
+	. "code.cloudfoundry.org/cf-routing-test-helpers/helpers"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
+	"github.com/gorilla/websocket"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+// dialWebSocket opens a WebSocket to the path of the app through the router.
+func dialWebSocket(appName, path string) (*websocket.Conn, *http.Response, error) {
+	uri := helpers.AppUri(appName, path, Config)
+	uri = "ws" + strings.TrimPrefix(uri, "http")
+
+	dialer := websocket.Dialer{
+		HandshakeTimeout: Config.DefaultTimeoutDuration(),
+		TLSClientConfig:  &tls.Config{InsecureSkipVerify: Config.GetSkipSSLValidation()},
+	}
+	return dialer.Dial(uri, nil)
+}
+
+// readMessage reads the next text message from the WebSocket, failing the
+// spec if none arrives within the default timeout.
+func readMessage(conn *websocket.Conn) string {
+	conn.SetReadDeadline(time.Now().Add(Config.DefaultTimeoutDuration()))
+	messageType, message, err := conn.ReadMessage()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(messageType).To(Equal(websocket.TextMessage))
+	return string(message)
+}
+
+var _ = RoutingDescribe("Long-lived connections", func() {
+	var appName string
+
+	BeforeEach(func() {
+		appName = random_name.CATSRandomName("APP")
+		PushApp(appName, assets.NewAssets().GoStreaming, Config.GetGoBuildpackName(), Config.GetAppsDomain(), Config.CfPushTimeoutDuration(), DEFAULT_MEMORY_LIMIT)
+
+		Eventually(func() string {
+			return helpers.CurlAppRoot(Config, appName)
+		}, Config.DefaultTimeoutDuration()).Should(ContainSubstring("go-streaming"))
+	})
+
+	AfterEach(func() {
+		AppReport(appName, Config.DefaultTimeoutDuration())
+
+		DeleteApp(appName, Config.DefaultTimeoutDuration())
+	})
+
+	Describe("WebSockets", func() {
+		var conn *websocket.Conn
+
+		BeforeEach(func() {
+			var (
+				response *http.Response
+				err      error
+			)
+			conn, response, err = dialWebSocket(appName, "/websocket")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusSwitchingProtocols))
+			Expect(strings.ToLower(response.Header.Get("Upgrade"))).To(Equal("websocket"))
+		})
+
+		AfterEach(func() {
+			conn.Close()
+		})
+
+		It("upgrades the connection through the router and relays messages in both directions", func() {
+			Expect(readMessage(conn)).To(Equal("connected to instance 0"))
+
+			for _, message := range []string{"first message", "second message"} {
+				Expect(conn.WriteMessage(websocket.TextMessage, []byte(message))).To(Succeed())
+				Expect(readMessage(conn)).To(Equal(message))
+			}
+		})
+
+		It("keeps an idle connection open", func() {
+			Expect(readMessage(conn)).To(Equal("connected to instance 0"))
+
+			time.Sleep(Config.IdleConnectionTimeoutDuration())
+
+			Expect(conn.WriteMessage(websocket.TextMessage, []byte("still there?"))).To(Succeed())
+			Expect(readMessage(conn)).To(Equal("still there?"))
+		})
+
+		It("closes the connection cleanly when the app is stopped", func() {
+			Expect(readMessage(conn)).To(Equal("connected to instance 0"))
+
+			Expect(cf.Cf("stop", appName).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+
+			conn.SetReadDeadline(time.Now().Add(Config.DefaultTimeoutDuration()))
+			_, _, err := conn.ReadMessage()
+			Expect(websocket.IsCloseError(err, websocket.CloseGoingAway)).To(BeTrue(), "expected a going-away close frame, got: %v", err)
+		})
+	})
+
+	Describe("server-sent events", func() {
+		It("streams each event to the client as it is sent", func() {
+			session := helpers.Curl(Config, "-N", "-i", helpers.AppUri(appName, "/events?count=5&interval_ms=1000", Config))
+
+			Eventually(session, Config.DefaultTimeoutDuration()).Should(Say("(?i)content-type: text/event-stream"))
+			Eventually(session, Config.DefaultTimeoutDuration()).Should(Say("id: 0\ndata: event 0\n"))
+			Expect(session).NotTo(Exit())
+
+			Eventually(session, Config.DefaultTimeoutDuration()).Should(Say("id: 4\ndata: event 4\n"))
+			Eventually(session, Config.DefaultTimeoutDuration()).Should(Exit(0))
+		})
+	})
+
+	Describe("chunked responses", func() {
+		It("streams each chunk to the client as it is written", func() {
+			session := helpers.Curl(Config, "-N", "-i", helpers.AppUri(appName, "/stream?count=5&interval_ms=1000", Config))
+
+			Eventually(session, Config.DefaultTimeoutDuration()).Should(Say("(?i)transfer-encoding: chunked"))
+			Eventually(session, Config.DefaultTimeoutDuration()).Should(Say("chunk 0\n"))
+			Expect(session).NotTo(Exit())
+
+			Eventually(session, Config.DefaultTimeoutDuration()).Should(Say("chunk 4\n"))
+			Eventually(session, Config.DefaultTimeoutDuration()).Should(Exit(0))
+		})
+	})
+})
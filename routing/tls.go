@@ -0,0 +1,59 @@
+package routing
+
+import (
+	"net"
+	"strings"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+
+	. "code.cloudfoundry.org/cf-routing-test-helpers/helpers"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/certificates"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+var _ = RoutingDescribe("TLS on the apps domain", func() {
+	BeforeEach(func() {
+		if Config.GetAppsDomainCaBundle() == "" {
+			Skip(`Skipping this test because Config.AppsDomainCaBundle is not set.`)
+		}
+	})
+
+	It("presents a certificate chain to the configured CA bundle", func() {
+		serverName := strings.ToLower(random_name.CATSRandomName("ROUTE")) + "." + Config.GetAppsDomain()
+
+		chain, err := certificates.ServerChain(net.JoinHostPort(serverName, "443"), serverName, Config.DefaultTimeoutDuration())
+		Expect(err).NotTo(HaveOccurred())
+
+		roots, err := certificates.LoadCaBundle(Config.GetAppsDomainCaBundle())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(certificates.VerifyChain(chain, roots, serverName)).To(Succeed())
+	})
+
+	Context("when an app is pushed", func() {
+		var appName string
+
+		BeforeEach(func() {
+			appName = random_name.CATSRandomName("APP")
+			PushApp(appName, assets.NewAssets().Golang, Config.GetGoBuildpackName(), Config.GetAppsDomain(), Config.CfPushTimeoutDuration(), DEFAULT_MEMORY_LIMIT)
+		})
+
+		AfterEach(func() {
+			AppReport(appName, Config.DefaultTimeoutDuration())
+			DeleteApp(appName, Config.DefaultTimeoutDuration())
+		})
+
+		It("serves it over HTTPS to clients that trust the CA bundle", func() {
+			uri := "https://" + appName + "." + Config.GetAppsDomain()
+
+			Eventually(func() *Session {
+				return helpers.CurlSkipSSL(false, "--cacert", Config.GetAppsDomainCaBundle(), uri).Wait(Config.DefaultTimeoutDuration())
+			}, Config.DefaultTimeoutDuration()).Should(And(Exit(0), Say("go, world")))
+		})
+	})
+})
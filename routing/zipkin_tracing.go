@@ -1,6 +1,7 @@
 package routing
 
 import (
+	"encoding/json"
 	"fmt"
 	"regexp"
 
@@ -12,79 +13,112 @@ import (
 	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
-	"github.com/onsi/gomega/gbytes"
 	"github.com/onsi/gomega/gexec"
 )
 
+const b3IdPattern = `[0-9a-f]{16}`
+
+// b3Headers are the B3 headers that the go-zipkin asset received.
+type b3Headers struct {
+	TraceId      string `json:"trace_id"`
+	SpanId       string `json:"span_id"`
+	ParentSpanId string `json:"parent_span_id"`
+}
+
 var _ = ZipkinDescribe("Zipkin Tracing", func() {
-	var (
-		app1              string
-		helloRoutingAsset = assets.NewAssets().SpringSleuthZip
-		hostname          string
-	)
+	var appName string
+
+	// traceRequest sends a request with the given headers through the router
+	// and returns the B3 headers that reached the app.
+	traceRequest := func(headers ...string) b3Headers {
+		args := []string{}
+		for _, header := range headers {
+			args = append(args, "-H", header)
+		}
+
+		var received b3Headers
+		Eventually(func() error {
+			return json.Unmarshal([]byte(cf_helpers.CurlApp(Config, appName, "/", args...)), &received)
+		}, Config.DefaultTimeoutDuration()).Should(Succeed())
+		return received
+	}
+
+	// accessLogSpan waits for the access log line of the trace and returns
+	// its span and parent span IDs.
+	accessLogSpan := func(traceId string) (string, string) {
+		accessLog := regexp.MustCompile(fmt.Sprintf(`x_b3_traceid:"%s" x_b3_spanid:"([0-9a-f-]*)" x_b3_parentspanid:"([0-9a-f-]*)"`, traceId))
+
+		var matches []string
+		Eventually(func() []string {
+			logs := cf.Cf("logs", "--recent", appName).Wait(Config.DefaultTimeoutDuration())
+			Expect(logs).To(gexec.Exit(0))
+
+			matches = accessLog.FindStringSubmatch(string(logs.Out.Contents()))
+			return matches
+		}, Config.DefaultTimeoutDuration()).Should(HaveLen(3))
+		return matches[1], matches[2]
+	}
+
+	appLogs := func() string {
+		logs := cf.Cf("logs", "--recent", appName).Wait(Config.DefaultTimeoutDuration())
+		Expect(logs).To(gexec.Exit(0))
+		return string(logs.Out.Contents())
+	}
 
 	BeforeEach(func() {
-		app1 = random_name.CATSRandomName("APP")
-		helpers.PushApp(app1, helloRoutingAsset, Config.GetJavaBuildpackName(), Config.GetAppsDomain(), CF_JAVA_TIMEOUT, "512M")
-
-		hostname = app1
+		appName = random_name.CATSRandomName("APP")
+		helpers.PushApp(appName, assets.NewAssets().GoZipkin, Config.GetGoBuildpackName(), Config.GetAppsDomain(), Config.CfPushTimeoutDuration(), DEFAULT_MEMORY_LIMIT)
 	})
 
 	AfterEach(func() {
-		helpers.AppReport(app1, Config.DefaultTimeoutDuration())
-		helpers.DeleteApp(app1, Config.DefaultTimeoutDuration())
+		helpers.AppReport(appName, Config.DefaultTimeoutDuration())
+		helpers.DeleteApp(appName, Config.DefaultTimeoutDuration())
 	})
 
-	Context("when zipkin tracing is enabled", func() {
-		Context("when zipkin headers are not in the request", func() {
-			It("the sleuth error response has no error", func() {
-				var curlOutput string
-				// when req does not have headers
-				Eventually(func() string {
-					curlOutput = cf_helpers.CurlAppRoot(Config, hostname)
-					return curlOutput
-				}, Config.DefaultTimeoutDuration()).Should(ContainSubstring("parents:"))
-
-				appLogsSession := cf.Cf("logs", "--recent", app1)
-
-				Eventually(appLogsSession, Config.DefaultTimeoutDuration()).Should(gexec.Exit(0))
-
-				Eventually(appLogsSession.Out).Should(gbytes.Say("x_b3_traceid"))
-				parentSpanID := getID(`x_b3_parentspanid:"([0-9a-fA-F-]*)"`, string(appLogsSession.Out.Contents()))
+	Context("when zipkin headers are not in the request", func() {
+		It("starts a new trace with a root span", func() {
+			received := traceRequest()
 
-				Expect(parentSpanID).To(Equal("-"))
+			Expect(received.TraceId).To(MatchRegexp("^%s$", b3IdPattern))
+			Expect(received.SpanId).To(MatchRegexp("^%s$", b3IdPattern))
+			Expect(received.ParentSpanId).To(BeEmpty())
 
-				By("when request has zipkin trace headers")
+			spanId, parentSpanId := accessLogSpan(received.TraceId)
+			Expect(spanId).To(Equal(received.SpanId))
+			Expect(parentSpanId).To(Equal("-"))
 
-				traceID := "fee1f7ba6aeec41c"
+			Expect(appLogs()).To(ContainSubstring(`b3 trace_id:"%s" span_id:"%s" parent_span_id:""`, received.TraceId, received.SpanId))
+		})
+	})
 
-				header1 := fmt.Sprintf(`X-B3-TraceId: %s `, traceID)
-				header2 := `X-B3-SpanId: 579b36fd31cd8714`
-				Eventually(func() string {
-					curlOutput = cf_helpers.CurlApp(Config, hostname, "/", "-H", header1, "-H", header2)
-					return curlOutput
-				}, Config.DefaultTimeoutDuration()).Should(ContainSubstring("parents:"))
+	Context("when zipkin headers are in the request", func() {
+		const (
+			traceId      = "fee1f7ba6aeec41c"
+			spanId       = "579b36fd31cd8714"
+			parentSpanId = "2d1d7e7b9ac5f0a3"
+		)
 
-				appLogsSession = cf.Cf("logs", "--recent", hostname)
+		It("preserves the trace and starts a child span of the client's span", func() {
+			received := traceRequest("X-B3-TraceId: "+traceId, "X-B3-SpanId: "+spanId)
 
-				Eventually(appLogsSession, Config.DefaultTimeoutDuration()).Should(gexec.Exit(0))
+			Expect(received.TraceId).To(Equal(traceId))
+			Expect(received.ParentSpanId).To(Equal(spanId))
+			Expect(received.SpanId).To(MatchRegexp("^%s$", b3IdPattern))
+			Expect(received.SpanId).NotTo(Equal(spanId))
 
-				Expect(appLogsSession.Out).To(gbytes.Say(`x_b3_traceid:"fee1f7ba6aeec41c"`))
+			routerSpanId, routerParentSpanId := accessLogSpan(traceId)
+			Expect(routerSpanId).To(Equal(received.SpanId))
+			Expect(routerParentSpanId).To(Equal(spanId))
 
-				spanIDRegex := fmt.Sprintf("x_b3_traceid:\"%s\" x_b3_spanid:\"([0-9a-fA-F]*)\"", traceID)
+			Expect(appLogs()).To(ContainSubstring(`b3 trace_id:"%s" span_id:"%s" parent_span_id:"%s"`, traceId, received.SpanId, spanId))
+		})
 
-				appLogSpanID := getID(spanIDRegex, string(appLogsSession.Out.Contents()))
+		It("replaces the client's parent span with the client's span", func() {
+			received := traceRequest("X-B3-TraceId: "+traceId, "X-B3-SpanId: "+spanId, "X-B3-ParentSpanId: "+parentSpanId)
 
-				Expect(curlOutput).To(ContainSubstring(traceID))
-				Expect(curlOutput).To(ContainSubstring(fmt.Sprintf("parents: [%s]", appLogSpanID)))
-			})
+			Expect(received.TraceId).To(Equal(traceId))
+			Expect(received.ParentSpanId).To(Equal(spanId))
+			Expect(received.SpanId).NotTo(Equal(spanId))
 		})
 	})
 })
-
-func getID(logRegex, logLines string) string {
-	matches := regexp.MustCompile(logRegex).FindStringSubmatch(logLines)
-	Expect(matches).To(HaveLen(2))
-
-	return matches[1]
-}
@@ -0,0 +1,172 @@
+package service_instance_sharing
+
+import (
+	"fmt"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/workflowhelpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/app_helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/feature_flag_helpers"
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/matchers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/services"
+)
+
+const sharingFeatureFlag = "service_instance_sharing"
+
+// asAdminInSourceSpace runs cf as admin targeting the test space, which owns
+// the shared service instance. Sharing requires access to both spaces.
+func asAdminInSourceSpace(args ...string) *Session {
+	var session *Session
+	workflowhelpers.AsUser(TestSetup.AdminUserContext(), Config.DefaultTimeoutDuration(), func() {
+		source := TestSetup.RegularUserContext()
+		Expect(cf.Cf("target", "-o", source.Org, "-s", source.Space).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		session = cf.Cf(args...).Wait(Config.DefaultTimeoutDuration())
+	})
+	return session
+}
+
+var _ = ServiceInstanceSharingDescribe("Service instance sharing", func() {
+	var (
+		broker          services.ServiceBroker
+		instanceName    string
+		targetSetup     *workflowhelpers.ReproducibleTestSuiteSetup
+		targetUser      workflowhelpers.UserContext
+		appName         string
+		originalSharing bool
+	)
+
+	shareService := func() *Session {
+		return asAdminInSourceSpace("share-service", instanceName, "-o", targetUser.Org, "-s", targetUser.Space)
+	}
+
+	unshareService := func() *Session {
+		return asAdminInSourceSpace("unshare-service", instanceName, "-o", targetUser.Org, "-s", targetUser.Space, "-f")
+	}
+
+	BeforeEach(func() {
+		originalSharing = FetchFeatureFlags()[sharingFeatureFlag]
+		SetFeatureFlag(sharingFeatureFlag, true)
+
+		broker = services.NewServiceBroker(
+			random_name.CATSRandomName("BRKR"),
+			assets.NewAssets().GoServiceBroker,
+			TestSetup,
+		)
+		broker.Push(Config)
+		broker.Configure()
+		broker.Create()
+		broker.PublicizePlans()
+
+		instanceName = random_name.CATSRandomName("SVIN")
+		broker.CreateServiceInstance(instanceName)
+
+		// The target space belongs to a second user, who cannot see the test
+		// space. The regular user's CF_HOME is left untouched.
+		targetSetup = workflowhelpers.NewTestSuiteSetup(Config)
+		targetUser = targetSetup.RegularUserContext()
+		workflowhelpers.AsUser(targetSetup.AdminUserContext(), Config.DefaultTimeoutDuration(), func() {
+			targetSetup.TestSpace.Create()
+			targetSetup.TestUser.Create()
+			targetUser.AddUserToSpace()
+		})
+
+		appName = random_name.CATSRandomName("APP")
+	})
+
+	// The flag is global, so it is restored before the other cleanup, which
+	// may fail.
+	AfterEach(func() {
+		SetFeatureFlag(sharingFeatureFlag, originalSharing)
+	})
+
+	AfterEach(func() {
+		app_helpers.AppReport(broker.Name, Config.DefaultTimeoutDuration())
+
+		workflowhelpers.AsUser(targetUser, Config.DefaultTimeoutDuration(), func() {
+			Expect(cf.Cf("delete", appName, "-f", "-r").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		})
+		unshareService()
+
+		Expect(cf.Cf("delete-service", instanceName, "-f").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		broker.Destroy()
+
+		workflowhelpers.AsUser(targetSetup.AdminUserContext(), Config.DefaultTimeoutDuration(), func() {
+			targetSetup.TestUser.Destroy()
+			targetSetup.TestSpace.Destroy()
+		})
+	})
+
+	It("lets an app in another space bind to the shared instance until it is unshared", func() {
+		share := shareService()
+		Expect(share).To(Exit(0))
+
+		Expect(asAdminInSourceSpace("service", instanceName)).To(Say("%s", targetUser.Space))
+
+		var appGuid string
+		workflowhelpers.AsUser(targetUser, Config.DefaultTimeoutDuration(), func() {
+			servicesList := cf.Cf("services").Wait(Config.DefaultTimeoutDuration())
+			Expect(servicesList).To(Exit(0))
+			Expect(servicesList.Out.Contents()).To(ContainSubstring(instanceName))
+
+			Expect(cf.Cf("push", appName,
+				"--no-start",
+				"-b", Config.GetRubyBuildpackName(),
+				"-m", DEFAULT_MEMORY_LIMIT,
+				"-p", assets.NewAssets().Dora,
+				"-d", Config.GetAppsDomain(),
+			).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			app_helpers.SetBackend(appName)
+			appGuid = app_helpers.GetAppGuid(appName)
+
+			Expect(cf.Cf("bind-service", appName, instanceName).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			Expect(cf.Cf("start", appName).Wait(Config.CfPushTimeoutDuration())).To(Exit(0))
+		})
+
+		vcapServices := helpers.CurlApp(Config, appName, "/env/VCAP_SERVICES")
+		Expect(vcapServices).To(ContainSubstring(instanceName))
+		Expect(vcapServices).To(ContainSubstring("fake-password"))
+		Expect(broker.Requests()).To(HaveReceivedBind(broker.SyncPlans[0].ID))
+
+		Expect(unshareService()).To(Exit(0))
+
+		workflowhelpers.AsUser(targetUser, Config.DefaultTimeoutDuration(), func() {
+			bindings := cf.Cf("curl", fmt.Sprintf("/v2/apps/%s/service_bindings", appGuid)).Wait(Config.DefaultTimeoutDuration())
+			Expect(bindings).To(Exit(0))
+			Expect(bindings).To(Say(`"total_results": 0`))
+
+			servicesList := cf.Cf("services").Wait(Config.DefaultTimeoutDuration())
+			Expect(servicesList).To(Exit(0))
+			Expect(servicesList.Out.Contents()).NotTo(ContainSubstring(instanceName))
+		})
+		Expect(broker.Requests()).To(HaveReceivedUnbind(broker.SyncPlans[0].ID))
+	})
+
+	Context("when the service is not shareable", func() {
+		BeforeEach(func() {
+			broker.ConfigureServiceMetadata(map[string]interface{}{"shareable": false})
+			broker.Update()
+		})
+
+		It("refuses to share the instance", func() {
+			share := shareService()
+			Expect(share).To(Exit(1))
+			Expect(share.Out.Contents()).To(ContainSubstring("does not support service instance sharing"))
+
+			workflowhelpers.AsUser(targetUser, Config.DefaultTimeoutDuration(), func() {
+				servicesList := cf.Cf("services").Wait(Config.DefaultTimeoutDuration())
+				Expect(servicesList).To(Exit(0))
+				Expect(servicesList.Out.Contents()).NotTo(ContainSubstring(instanceName))
+			})
+		})
+	})
+})
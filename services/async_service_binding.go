@@ -0,0 +1,156 @@
+package services_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/app_helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/matchers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/services"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+type ServiceBindingsResponse struct {
+	Resources []struct {
+		Metadata struct {
+			Guid string `json:"guid"`
+		} `json:"metadata"`
+		Entity struct {
+			Credentials   map[string]interface{} `json:"credentials"`
+			LastOperation LastOperation          `json:"last_operation"`
+		} `json:"entity"`
+	} `json:"resources"`
+}
+
+var _ = ServicesDescribe("Asynchronous Service Bindings", func() {
+	var (
+		broker       ServiceBroker
+		instanceName string
+		appName      string
+		appGuid      string
+	)
+
+	const asyncOperationPollInterval = 5 * time.Second
+
+	getBindings := func() ServiceBindingsResponse {
+		curl := cf.Cf("curl", fmt.Sprintf("/v2/apps/%s/service_bindings", appGuid)).Wait(Config.DefaultTimeoutDuration())
+		Expect(curl).To(Exit(0))
+
+		var bindings ServiceBindingsResponse
+		Expect(json.Unmarshal(curl.Out.Contents(), &bindings)).To(Succeed())
+		return bindings
+	}
+
+	bindingState := func() string {
+		bindings := getBindings()
+		if len(bindings.Resources) == 0 {
+			return "gone"
+		}
+		return bindings.Resources[0].Entity.LastOperation.State
+	}
+
+	BeforeEach(func() {
+		broker = NewServiceBroker(
+			random_name.CATSRandomName("BRKR"),
+			assets.NewAssets().GoServiceBroker,
+			TestSetup,
+		)
+		broker.Push(Config)
+		broker.Configure()
+		broker.Create()
+		broker.PublicizePlans()
+
+		instanceName = random_name.CATSRandomName("SVIN")
+		createService := cf.Cf("create-service", broker.Service.Name, broker.AsyncPlans[0].Name, instanceName).Wait(Config.DefaultTimeoutDuration())
+		Expect(createService).To(Exit(0))
+		Eventually(func() *Session {
+			return cf.Cf("service", instanceName).Wait(Config.DefaultTimeoutDuration())
+		}, Config.AsyncServiceOperationTimeoutDuration(), asyncOperationPollInterval).Should(Say("create succeeded"))
+
+		appName = random_name.CATSRandomName("APP")
+		Expect(cf.Cf("push", appName, "--no-start", "-b", Config.GetRubyBuildpackName(), "-m", DEFAULT_MEMORY_LIMIT, "-p", assets.NewAssets().Dora, "-d", Config.GetAppsDomain()).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		app_helpers.SetBackend(appName)
+		appGuid = app_helpers.GetAppGuid(appName)
+	})
+
+	AfterEach(func() {
+		app_helpers.AppReport(appName, Config.DefaultTimeoutDuration())
+		app_helpers.AppReport(broker.Name, Config.DefaultTimeoutDuration())
+
+		Expect(cf.Cf("unbind-service", appName, instanceName).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		Eventually(bindingState, Config.AsyncServiceOperationTimeoutDuration(), asyncOperationPollInterval).Should(Equal("gone"))
+
+		Expect(cf.Cf("delete", appName, "-f", "-r").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		Expect(cf.Cf("delete-service", instanceName, "-f").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		Eventually(func() *Session {
+			return cf.Cf("service", instanceName).Wait(Config.DefaultTimeoutDuration())
+		}, Config.AsyncServiceOperationTimeoutDuration(), asyncOperationPollInterval).Should(Say("not found"))
+
+		broker.Destroy()
+	})
+
+	It("binds asynchronously and fetches the binding once it has succeeded", func() {
+		bindService := cf.Cf("bind-service", appName, instanceName).Wait(Config.DefaultTimeoutDuration())
+		Expect(bindService).To(Exit(0))
+		Expect(bindService).To(Say("in progress"))
+
+		Expect(bindingState()).To(Equal("in progress"))
+		Expect(cf.Cf("service", instanceName).Wait(Config.DefaultTimeoutDuration())).To(Say("create in progress"))
+
+		Eventually(bindingState, Config.AsyncServiceOperationTimeoutDuration(), asyncOperationPollInterval).Should(Equal("succeeded"))
+		Expect(cf.Cf("service", instanceName).Wait(Config.DefaultTimeoutDuration())).To(Say("create succeeded"))
+
+		Expect(getBindings().Resources[0].Entity.Credentials).To(HaveKeyWithValue("username", "fake-user"))
+
+		appEnv := cf.Cf("env", appName).Wait(Config.DefaultTimeoutDuration())
+		Expect(appEnv).To(Exit(0))
+		Expect(appEnv).To(Say("fake-password"))
+
+		requests := broker.Requests()
+		Expect(requests).To(HaveReceivedBind(broker.AsyncPlans[0].ID).WithAcceptsIncomplete())
+		Expect(requests).To(HaveReceivedBindingFetch(getBindings().Resources[0].Metadata.Guid))
+	})
+
+	Context("when there is an existing binding", func() {
+		BeforeEach(func() {
+			Expect(cf.Cf("bind-service", appName, instanceName).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			Eventually(bindingState, Config.AsyncServiceOperationTimeoutDuration(), asyncOperationPollInterval).Should(Equal("succeeded"))
+		})
+
+		It("unbinds asynchronously", func() {
+			unbindService := cf.Cf("unbind-service", appName, instanceName).Wait(Config.DefaultTimeoutDuration())
+			Expect(unbindService).To(Exit(0))
+			Expect(unbindService).To(Say("in progress"))
+
+			Expect(bindingState()).To(Equal("in progress"))
+			Expect(cf.Cf("service", instanceName).Wait(Config.DefaultTimeoutDuration())).To(Say("delete in progress"))
+
+			Eventually(bindingState, Config.AsyncServiceOperationTimeoutDuration(), asyncOperationPollInterval).Should(Equal("gone"))
+			Expect(cf.Cf("service", instanceName).Wait(Config.DefaultTimeoutDuration())).NotTo(Say("%s", appName))
+
+			Expect(broker.Requests()).To(HaveReceivedUnbind(broker.AsyncPlans[0].ID).WithAcceptsIncomplete())
+		})
+	})
+
+	Describe("service keys", func() {
+		It("are refused by plans that only bind asynchronously, since keys are created synchronously", func() {
+			keyName := random_name.CATSRandomName("SVC-KEY")
+			createKey := cf.Cf("create-service-key", instanceName, keyName).Wait(Config.DefaultTimeoutDuration())
+			Expect(createKey).To(Exit(1))
+			Expect(createKey.Out.Contents()).To(ContainSubstring("AsyncRequired"))
+
+			Expect(broker.Requests()).To(HaveReceivedBind(broker.AsyncPlans[0].ID))
+			Expect(cf.Cf("service-keys", instanceName).Wait(Config.DefaultTimeoutDuration())).NotTo(Say("%s", keyName))
+		})
+	})
+})
@@ -0,0 +1,104 @@
+package services_test
+
+import (
+	"path/filepath"
+	"time"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/workflowhelpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/services"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+)
+
+// Every enabled row of acceptance.csv becomes a case below, so new broker
+// failure modes can be covered by adding a row.
+var brokerCasesPath = filepath.Join(assets.NewAssets().ServiceBroker, "acceptance.csv")
+
+var _ = ServicesDescribe("Broker failure modes", func() {
+	var (
+		shared       = newSharedBroker(assets.NewAssets().ServiceBroker)
+		broker       ServiceBroker
+		instanceName string
+	)
+
+	createService := func() *Session {
+		return cf.Cf("create-service", broker.Service.Name, broker.SyncPlans[0].Name, instanceName)
+	}
+
+	actions := map[string]struct {
+		setup func()
+		run   func() *Session
+	}{
+		"provision": {
+			setup: func() {},
+			run:   createService,
+		},
+		"update": {
+			setup: func() { Expect(createService().Wait(Config.DefaultTimeoutDuration())).To(Exit(0)) },
+			run: func() *Session {
+				return cf.Cf("update-service", instanceName, "-p", broker.SyncPlans[1].Name)
+			},
+		},
+		"deprovision": {
+			setup: func() { Expect(createService().Wait(Config.DefaultTimeoutDuration())).To(Exit(0)) },
+			run: func() *Session {
+				return cf.Cf("delete-service", instanceName, "-f")
+			},
+		},
+	}
+
+	BeforeEach(func() {
+		broker = shared.Get()
+		broker.Create()
+		broker.PublicizePlans()
+
+		instanceName = random_name.CATSRandomName("SVIN")
+	})
+
+	// Purging the service offering removes whatever instance the case left
+	// behind, whichever state it is in.
+	AfterEach(func() {
+		workflowhelpers.AsUser(TestSetup.AdminUserContext(), Config.DefaultTimeoutDuration(), func() {
+			Expect(cf.Cf("purge-service-offering", broker.Service.Name, "-f").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		})
+		broker.Delete()
+	})
+
+	cases, err := LoadBrokerCases(brokerCasesPath)
+	if err != nil {
+		It("loads the broker case table", func() {
+			Fail(err.Error())
+		})
+		return
+	}
+
+	entries := []table.TableEntry{}
+	for _, brokerCase := range cases {
+		entries = append(entries, table.Entry(brokerCase.String(), brokerCase))
+	}
+
+	table.DescribeTable("from "+brokerCasesPath,
+		func(brokerCase BrokerCase) {
+			action := actions[brokerCase.Action]
+			action.setup()
+
+			broker.ConfigureBehavior(brokerCase.Action, brokerCase.Behavior())
+
+			// Cases where the broker sleeps wait for Cloud Controller's broker
+			// client to time out.
+			timeout := Config.DefaultTimeoutDuration() + time.Duration(brokerCase.SleepSeconds*float64(time.Second))
+			session := action.run().Wait(timeout)
+			Expect(session).To(Exit(brokerCase.ExpectedExitCode))
+			Expect(string(session.Out.Contents()) + string(session.Err.Contents())).To(ContainSubstring(brokerCase.ExpectedOutput))
+		},
+		entries...,
+	)
+})
@@ -0,0 +1,139 @@
+package services_test
+
+import (
+	"path"
+	"regexp"
+	"time"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry/cf-acceptance-tests/assets/go-service-broker/broker"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/app_helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/matchers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/services"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+)
+
+// requestedID returns the id of the instance or binding in the first request
+// of the journal with the given method whose path matches pathPattern.
+func requestedID(requests []broker.Request, method string, pathPattern *regexp.Regexp) string {
+	for _, request := range requests {
+		if request.Method == method && pathPattern.MatchString(request.Path) {
+			return path.Base(request.Path)
+		}
+	}
+	Fail("broker did not receive " + method + " " + pathPattern.String())
+	return ""
+}
+
+var _ = ServicesDescribe("Orphan mitigation", func() {
+	var (
+		serviceBroker ServiceBroker
+		instanceName  string
+	)
+
+	const orphanMitigationPollInterval = 5 * time.Second
+
+	var (
+		instancePath = regexp.MustCompile(`^/v2/service_instances/[^/]+$`)
+		bindingPath  = regexp.MustCompile(`^/v2/service_instances/[^/]+/service_bindings/[^/]+$`)
+	)
+
+	// timingOut is a function since Config is only loaded once the specs run.
+	timingOut := func() map[string]interface{} {
+		return map[string]interface{}{
+			"sleep_seconds": (Config.BrokerClientTimeoutDuration() + 10*time.Second).Seconds(),
+			"status":        200,
+			"body":          map[string]interface{}{},
+		}
+	}
+
+	failing := map[string]interface{}{
+		"sleep_seconds": 0,
+		"status":        500,
+		"body":          map[string]interface{}{"description": "the broker failed"},
+	}
+
+	BeforeEach(func() {
+		serviceBroker = NewServiceBroker(
+			random_name.CATSRandomName("BRKR"),
+			assets.NewAssets().GoServiceBroker,
+			TestSetup,
+		)
+		serviceBroker.Push(Config)
+		serviceBroker.Configure()
+		serviceBroker.Create()
+		serviceBroker.PublicizePlans()
+
+		instanceName = random_name.CATSRandomName("SVIN")
+	})
+
+	AfterEach(func() {
+		app_helpers.AppReport(serviceBroker.Name, Config.DefaultTimeoutDuration())
+
+		serviceBroker.Destroy()
+	})
+
+	Context("when provisioning fails", func() {
+		expectDeprovisionAfterFailedProvision := func() {
+			createService := cf.Cf("create-service", serviceBroker.Service.Name, serviceBroker.SyncPlans[0].Name, instanceName).
+				Wait(Config.DefaultTimeoutDuration() + Config.BrokerClientTimeoutDuration())
+			Expect(createService).To(Exit(1))
+
+			instanceID := requestedID(serviceBroker.Requests(), "PUT", instancePath)
+			Eventually(serviceBroker.Requests, Config.AsyncServiceOperationTimeoutDuration(), orphanMitigationPollInterval).
+				Should(HaveReceivedDeprovision(serviceBroker.SyncPlans[0].ID).ForID(instanceID))
+		}
+
+		It("deprovisions the instance when the broker responds with a server error", func() {
+			serviceBroker.ConfigureBehavior("provision", failing)
+			expectDeprovisionAfterFailedProvision()
+		})
+
+		It("deprovisions the instance when the broker times out", func() {
+			serviceBroker.ConfigureBehavior("provision", timingOut())
+			expectDeprovisionAfterFailedProvision()
+		})
+	})
+
+	Context("when binding fails", func() {
+		var appName string
+
+		BeforeEach(func() {
+			Expect(cf.Cf("create-service", serviceBroker.Service.Name, serviceBroker.SyncPlans[0].Name, instanceName).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+
+			appName = random_name.CATSRandomName("APP")
+			Expect(cf.Cf("push", appName, "--no-start", "-b", Config.GetRubyBuildpackName(), "-m", DEFAULT_MEMORY_LIMIT, "-p", assets.NewAssets().Dora, "-d", Config.GetAppsDomain()).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		})
+
+		AfterEach(func() {
+			app_helpers.AppReport(appName, Config.DefaultTimeoutDuration())
+			Expect(cf.Cf("delete", appName, "-f", "-r").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		})
+
+		expectUnbindAfterFailedBind := func() {
+			bindService := cf.Cf("bind-service", appName, instanceName).Wait(Config.DefaultTimeoutDuration() + Config.BrokerClientTimeoutDuration())
+			Expect(bindService).To(Exit(1))
+
+			bindingID := requestedID(serviceBroker.Requests(), "PUT", bindingPath)
+			Eventually(serviceBroker.Requests, Config.AsyncServiceOperationTimeoutDuration(), orphanMitigationPollInterval).
+				Should(HaveReceivedUnbind(serviceBroker.SyncPlans[0].ID).ForID(bindingID))
+		}
+
+		It("unbinds when the broker responds with a server error", func() {
+			serviceBroker.ConfigureBehavior("bind", failing)
+			expectUnbindAfterFailedBind()
+		})
+
+		It("unbinds when the broker times out", func() {
+			serviceBroker.ConfigureBehavior("bind", timingOut())
+			expectUnbindAfterFailedBind()
+		})
+	})
+})
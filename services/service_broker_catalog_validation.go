@@ -0,0 +1,200 @@
+package services_test
+
+import (
+	"strings"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/workflowhelpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/app_helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/services"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+)
+
+// catalogIsInvalid starts every error Cloud Controller reports for a catalog
+// that fails validation. The errors for each service follow on their own
+// lines.
+const catalogIsInvalid = "Service broker catalog is invalid"
+
+type invalidCatalog struct {
+	modify func(*Catalog)
+	errors []string
+}
+
+var invalidCatalogs = []table.TableEntry{
+	table.Entry("duplicate plan ids", invalidCatalog{
+		modify: func(catalog *Catalog) {
+			plans := catalog.Services[0].Plans
+			plans[1].ID = plans[0].ID
+		},
+		errors: []string{"Plan ids must be unique"},
+	}),
+	table.Entry("a service without a name", invalidCatalog{
+		modify: func(catalog *Catalog) { catalog.Services[0].Name = "" },
+		errors: []string{"Service name is required"},
+	}),
+	table.Entry("a plan without an id or description", invalidCatalog{
+		modify: func(catalog *Catalog) {
+			catalog.Services[0].Plans[0].ID = ""
+			catalog.Services[0].Plans[0].Description = ""
+		},
+		errors: []string{"Plan id is required", "Plan description is required"},
+	}),
+	table.Entry("a plan schema without $schema", invalidCatalog{
+		modify: func(catalog *Catalog) {
+			catalog.Services[0].Plans[0].Schemas = map[string]interface{}{
+				"service_instance": map[string]interface{}{
+					"create": map[string]interface{}{
+						"parameters": map[string]interface{}{"type": "object"},
+					},
+				},
+			}
+		},
+		errors: []string{"Schema service_instance.create.parameters must have $schema key but was not present"},
+	}),
+	table.Entry("a plan schema that is not an object", invalidCatalog{
+		modify: func(catalog *Catalog) {
+			catalog.Services[0].Plans[0].Schemas = map[string]interface{}{
+				"service_instance": map[string]interface{}{
+					"create": map[string]interface{}{"parameters": "not-a-schema"},
+				},
+			}
+		},
+		errors: []string{`Schemas service_instance.create.parameters must be a hash, but has value "not-a-schema"`},
+	}),
+	table.Entry("a plan name longer than 255 characters", invalidCatalog{
+		modify: func(catalog *Catalog) { catalog.Services[0].Plans[0].Name = strings.Repeat("p", 256) },
+		errors: []string{"Plan name must be at most 255 characters"},
+	}),
+}
+
+var _ = ServicesDescribe("Service broker catalog validation", func() {
+	var (
+		shared = newSharedBroker(assets.NewAssets().GoServiceBroker)
+		broker ServiceBroker
+	)
+
+	runAsAdmin := func(args ...string) *Session {
+		var session *Session
+		workflowhelpers.AsUser(TestSetup.AdminUserContext(), Config.DefaultTimeoutDuration(), func() {
+			session = cf.Cf(args...).Wait(Config.DefaultTimeoutDuration())
+		})
+		return session
+	}
+
+	expectRejected := func(session *Session, errors []string) {
+		Expect(session).To(Exit(1))
+		output := string(session.Out.Contents())
+		Expect(output).To(ContainSubstring(catalogIsInvalid))
+		for _, message := range errors {
+			Expect(output).To(ContainSubstring(message))
+		}
+	}
+
+	BeforeEach(func() {
+		broker = shared.Get()
+	})
+
+	Describe("create-service-broker", func() {
+		// A catalog that Cloud Controller unexpectedly accepts leaves the
+		// broker registered.
+		AfterEach(func() {
+			Expect(runAsAdmin("delete-service-broker", broker.Name, "-f")).To(Exit(0))
+		})
+
+		table.DescribeTable("refuses a broker whose catalog has",
+			func(invalid invalidCatalog) {
+				catalog := broker.Catalog()
+				invalid.modify(&catalog)
+				broker.ConfigureCatalog(catalog)
+
+				createBroker := runAsAdmin("create-service-broker", broker.Name, "username", "password", helpers.AppUri(broker.Name, "", Config))
+				expectRejected(createBroker, invalid.errors)
+
+				Expect(runAsAdmin("service-brokers").Out.Contents()).NotTo(ContainSubstring(broker.Name))
+			},
+			invalidCatalogs...,
+		)
+	})
+
+	Describe("update-service-broker", func() {
+		updateBroker := func() *Session {
+			return runAsAdmin("update-service-broker", broker.Name, "username", "password", helpers.AppUri(broker.Name, "", Config))
+		}
+
+		BeforeEach(func() {
+			broker.ConfigureCatalog(broker.Catalog())
+			broker.Create()
+		})
+
+		AfterEach(func() {
+			Expect(runAsAdmin("purge-service-offering", broker.Service.Name, "-f")).To(Exit(0))
+			broker.Delete()
+		})
+
+		table.DescribeTable("refuses a catalog that has",
+			func(invalid invalidCatalog) {
+				catalog := broker.Catalog()
+				invalid.modify(&catalog)
+				broker.ConfigureCatalog(catalog)
+
+				expectRejected(updateBroker(), invalid.errors)
+
+				serviceAccess := runAsAdmin("service-access", "-b", broker.Name)
+				Expect(serviceAccess).To(Exit(0))
+				for _, plan := range broker.Plans() {
+					Expect(serviceAccess.Out.Contents()).To(ContainSubstring(plan.Name))
+				}
+			},
+			invalidCatalogs...,
+		)
+
+		Context("when a plan has bindings", func() {
+			var (
+				appName      string
+				instanceName string
+			)
+
+			BeforeEach(func() {
+				broker.PublicizePlans()
+
+				instanceName = random_name.CATSRandomName("SVIN")
+				Expect(cf.Cf("create-service", broker.Service.Name, broker.SyncPlans[0].Name, instanceName).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+
+				appName = random_name.CATSRandomName("APP")
+				Expect(cf.Cf("push", appName, "--no-start", "-b", Config.GetRubyBuildpackName(), "-m", DEFAULT_MEMORY_LIMIT, "-p", assets.NewAssets().Dora, "-d", Config.GetAppsDomain()).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+				Expect(cf.Cf("bind-service", appName, instanceName).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			})
+
+			AfterEach(func() {
+				app_helpers.AppReport(appName, Config.DefaultTimeoutDuration())
+
+				Expect(cf.Cf("delete", appName, "-f", "-r").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			})
+
+			It("refuses to make the plan non-bindable", func() {
+				notBindable := false
+				catalog := broker.Catalog()
+				catalog.Services[0].Plans[0].Bindable = &notBindable
+				broker.ConfigureCatalog(catalog)
+
+				update := updateBroker()
+				Expect(update).To(Exit(1))
+				Expect(update.Out.Contents()).To(ContainSubstring("Service broker catalog is incompatible"))
+				Expect(update.Out.Contents()).To(ContainSubstring("Plan " + broker.SyncPlans[0].Name + " cannot be made non-bindable because it has bindings"))
+
+				service := cf.Cf("service", instanceName).Wait(Config.DefaultTimeoutDuration())
+				Expect(service).To(Exit(0))
+				Expect(service.Out.Contents()).To(ContainSubstring(appName))
+			})
+		})
+	})
+})
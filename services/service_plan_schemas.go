@@ -0,0 +1,157 @@
+package services_test
+
+import (
+	"encoding/json"
+	"fmt"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/app_helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/matchers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/services"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+)
+
+var _ = ServicesDescribe("Service plan schemas", func() {
+	var (
+		broker       ServiceBroker
+		schemas      map[string]interface{}
+		instanceName string
+	)
+
+	sizeSchema := ObjectSchema(map[string]interface{}{
+		"size": map[string]interface{}{"type": "string"},
+	}, "size")
+
+	// Cloud Controller only exposes the schemas to clients. It passes
+	// parameters through to the broker unchanged, whether they conform or not.
+	conforming := map[string]interface{}{"size": "small"}
+	nonConforming := map[string]interface{}{"size": 5}
+
+	asJSON := func(parameters map[string]interface{}) string {
+		encoded, err := json.Marshal(parameters)
+		Expect(err).NotTo(HaveOccurred())
+		return string(encoded)
+	}
+
+	BeforeEach(func() {
+		broker = NewServiceBroker(
+			random_name.CATSRandomName("BRKR"),
+			assets.NewAssets().GoServiceBroker,
+			TestSetup,
+		)
+		broker.Push(Config)
+		broker.Configure()
+
+		schemas = PlanSchemas{
+			ServiceInstanceCreate: sizeSchema,
+			ServiceInstanceUpdate: sizeSchema,
+			ServiceBindingCreate:  sizeSchema,
+		}.Catalog()
+
+		catalog := broker.Catalog()
+		catalog.Services[0].Plans[0].Schemas = schemas
+		broker.ConfigureCatalog(catalog)
+		broker.Create()
+		broker.PublicizePlans()
+
+		instanceName = random_name.CATSRandomName("SVIN")
+	})
+
+	AfterEach(func() {
+		app_helpers.AppReport(broker.Name, Config.DefaultTimeoutDuration())
+
+		broker.Destroy()
+	})
+
+	It("exposes the schemas of each plan through the service plans endpoint", func() {
+		var plans struct {
+			Resources []struct {
+				Entity struct {
+					Schemas map[string]interface{} `json:"schemas"`
+				} `json:"entity"`
+			} `json:"resources"`
+		}
+
+		curl := cf.Cf("curl", fmt.Sprintf("/v2/service_plans?q=unique_id:%s", broker.SyncPlans[0].ID)).Wait(Config.DefaultTimeoutDuration())
+		Expect(curl).To(Exit(0))
+		Expect(json.Unmarshal(curl.Out.Contents(), &plans)).To(Succeed())
+		Expect(plans.Resources).To(HaveLen(1))
+
+		expected, err := json.Marshal(schemas)
+		Expect(err).NotTo(HaveOccurred())
+		served, err := json.Marshal(plans.Resources[0].Entity.Schemas)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(served).To(MatchJSON(expected))
+	})
+
+	Describe("creating a service instance", func() {
+		It("succeeds with conforming parameters", func() {
+			createService := cf.Cf("create-service", broker.Service.Name, broker.SyncPlans[0].Name, instanceName, "-c", asJSON(conforming)).Wait(Config.DefaultTimeoutDuration())
+			Expect(createService).To(Exit(0))
+
+			Expect(broker.Requests()).To(HaveReceivedProvision(broker.SyncPlans[0].ID).WithParameters(conforming))
+		})
+
+		It("leaves validating non-conforming parameters to the broker", func() {
+			createService := cf.Cf("create-service", broker.Service.Name, broker.SyncPlans[0].Name, instanceName, "-c", asJSON(nonConforming)).Wait(Config.DefaultTimeoutDuration())
+			Expect(createService).To(Exit(0))
+
+			Expect(broker.Requests()).To(HaveReceivedProvision(broker.SyncPlans[0].ID).WithParameters(nonConforming))
+		})
+	})
+
+	Context("when there is a service instance", func() {
+		BeforeEach(func() {
+			Expect(cf.Cf("create-service", broker.Service.Name, broker.SyncPlans[0].Name, instanceName, "-c", asJSON(conforming)).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		})
+
+		Describe("updating it", func() {
+			It("succeeds with conforming parameters", func() {
+				updated := map[string]interface{}{"size": "large"}
+				Expect(cf.Cf("update-service", instanceName, "-c", asJSON(updated)).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+
+				Expect(broker.Requests()).To(HaveReceivedUpdate(broker.SyncPlans[0].ID).WithParameters(updated))
+			})
+
+			It("leaves validating non-conforming parameters to the broker", func() {
+				Expect(cf.Cf("update-service", instanceName, "-c", asJSON(nonConforming)).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+
+				Expect(broker.Requests()).To(HaveReceivedUpdate(broker.SyncPlans[0].ID).WithParameters(nonConforming))
+			})
+		})
+
+		Describe("binding an app to it", func() {
+			var appName string
+
+			BeforeEach(func() {
+				appName = random_name.CATSRandomName("APP")
+				Expect(cf.Cf("push", appName, "--no-start", "-b", Config.GetRubyBuildpackName(), "-m", DEFAULT_MEMORY_LIMIT, "-p", assets.NewAssets().Dora, "-d", Config.GetAppsDomain()).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			})
+
+			AfterEach(func() {
+				app_helpers.AppReport(appName, Config.DefaultTimeoutDuration())
+
+				Expect(cf.Cf("delete", appName, "-f", "-r").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			})
+
+			It("succeeds with conforming parameters", func() {
+				Expect(cf.Cf("bind-service", appName, instanceName, "-c", asJSON(conforming)).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+
+				Expect(broker.Requests()).To(HaveReceivedBind(broker.SyncPlans[0].ID).WithParameters(conforming))
+			})
+
+			It("leaves validating non-conforming parameters to the broker", func() {
+				Expect(cf.Cf("bind-service", appName, instanceName, "-c", asJSON(nonConforming)).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+
+				Expect(broker.Requests()).To(HaveReceivedBind(broker.SyncPlans[0].ID).WithParameters(nonConforming))
+			})
+		})
+	})
+})
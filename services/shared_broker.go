@@ -0,0 +1,49 @@
+package services_test
+
+import (
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/app_helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/services"
+
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+)
+
+// sharedBroker is a broker app that the specs of a Describe share, so that
+// table entries do not push a broker each.
+type sharedBroker struct {
+	path   string
+	broker *ServiceBroker
+}
+
+func newSharedBroker(path string) *sharedBroker {
+	return &sharedBroker{path: path}
+}
+
+// Get pushes and configures the broker app the first time it is called on
+// this node, and resets the broker on every later call. The app is deleted
+// once the suite has run; registering the broker is left to the caller.
+func (s *sharedBroker) Get() ServiceBroker {
+	if s.broker != nil {
+		s.broker.Reset()
+		return *s.broker
+	}
+
+	broker := NewServiceBroker(
+		random_name.CATSRandomName("BRKR"),
+		s.path,
+		TestSetup,
+	)
+	broker.Push(Config)
+	broker.Configure()
+	s.broker = &broker
+
+	AfterSuiteCleanup(func() {
+		app_helpers.AppReport(broker.Name, Config.DefaultTimeoutDuration())
+		Expect(cf.Cf("delete", broker.Name, "-f", "-r").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+	})
+	return broker
+}
@@ -0,0 +1,182 @@
+package tcp_routing
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+
+	. "code.cloudfoundry.org/cf-routing-test-helpers/helpers"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/workflowhelpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/skip_messages"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+)
+
+const tcpDialTimeout = 5 * time.Second
+
+// expectTcpDomain fails unless the operator has created the TCP domain. CATS
+// never creates or deletes it, since test nodes running in parallel share it.
+func expectTcpDomain(domain string) {
+	workflowhelpers.AsUser(TestSetup.AdminUserContext(), Config.DefaultTimeoutDuration(), func() {
+		Expect(GetGuid(fmt.Sprintf("/v2/shared_domains?q=name:%s", domain), Config.DefaultTimeoutDuration())).NotTo(BeEmpty(),
+			"tcp_domain %s must be a shared domain on a TCP router group", domain)
+	})
+}
+
+// useQuotaWithReservedRoutePorts assigns the test org a copy of its quota that
+// does not limit TCP routes, and returns a function that gives the org its
+// own quota back. The org's quota may be owned by the operator, so it is
+// never changed in place.
+func useQuotaWithReservedRoutePorts() func() {
+	var orgGuid, originalQuotaGuid, quotaGuid string
+
+	workflowhelpers.AsUser(TestSetup.AdminUserContext(), Config.DefaultTimeoutDuration(), func() {
+		orgGuid = GetGuid(fmt.Sprintf("/v2/organizations?q=name:%s", TestSetup.RegularUserContext().Org), Config.DefaultTimeoutDuration())
+		Expect(orgGuid).NotTo(BeEmpty())
+
+		var org struct {
+			Entity struct {
+				QuotaDefinitionGuid string `json:"quota_definition_guid"`
+			} `json:"entity"`
+		}
+		curl := cf.Cf("curl", "/v2/organizations/"+orgGuid).Wait(Config.DefaultTimeoutDuration())
+		Expect(curl).To(Exit(0))
+		Expect(json.Unmarshal(curl.Out.Contents(), &org)).To(Succeed())
+		originalQuotaGuid = org.Entity.QuotaDefinitionGuid
+
+		var quota struct {
+			Entity map[string]interface{} `json:"entity"`
+		}
+		curl = cf.Cf("curl", "/v2/quota_definitions/"+originalQuotaGuid).Wait(Config.DefaultTimeoutDuration())
+		Expect(curl).To(Exit(0))
+		Expect(json.Unmarshal(curl.Out.Contents(), &quota)).To(Succeed())
+
+		quota.Entity["name"] = random_name.CATSRandomName("QUOTA")
+		quota.Entity["total_reserved_route_ports"] = -1
+		body, err := json.Marshal(quota.Entity)
+		Expect(err).NotTo(HaveOccurred())
+
+		var created struct {
+			Metadata struct {
+				Guid string `json:"guid"`
+			} `json:"metadata"`
+		}
+		curl = cf.Cf("curl", "/v2/quota_definitions", "-X", "POST", "-d", string(body)).Wait(Config.DefaultTimeoutDuration())
+		Expect(curl).To(Exit(0))
+		Expect(json.Unmarshal(curl.Out.Contents(), &created)).To(Succeed())
+		quotaGuid = created.Metadata.Guid
+		Expect(quotaGuid).NotTo(BeEmpty(), string(curl.Out.Contents()))
+
+		Expect(cf.Cf("curl", "/v2/organizations/"+orgGuid, "-X", "PUT", "-d", fmt.Sprintf(`{"quota_definition_guid":%q}`, quotaGuid)).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+	})
+
+	return func() {
+		workflowhelpers.AsUser(TestSetup.AdminUserContext(), Config.DefaultTimeoutDuration(), func() {
+			Expect(cf.Cf("curl", "/v2/organizations/"+orgGuid, "-X", "PUT", "-d", fmt.Sprintf(`{"quota_definition_guid":%q}`, originalQuotaGuid)).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			Expect(cf.Cf("curl", "/v2/quota_definitions/"+quotaGuid, "-X", "DELETE").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		})
+	}
+}
+
+// sendLine sends a line to the TCP route and returns the reply of the echo
+// app, without its trailing newline.
+func sendLine(address, line string) (string, error) {
+	conn, err := net.DialTimeout("tcp", address, tcpDialTimeout)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(tcpDialTimeout))
+	if _, err := fmt.Fprintln(conn, line); err != nil {
+		return "", err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString('\n')
+	return strings.TrimSuffix(reply, "\n"), err
+}
+
+var _ = TcpRoutingDescribe("TCP routing", func() {
+	var (
+		appName      string
+		domain       string
+		port         uint16
+		address      string
+		restoreQuota func()
+	)
+
+	BeforeEach(func() {
+		if Config.GetBackend() != "diego" {
+			Skip(skip_messages.SkipDiegoMessage)
+		}
+
+		domain = Config.GetTcpDomain()
+		expectTcpDomain(domain)
+		restoreQuota = useQuotaWithReservedRoutePorts()
+
+		appName = random_name.CATSRandomName("APP")
+		PushAppNoStart(appName, assets.NewAssets().GoTcpEcho, Config.GetGoBuildpackName(), Config.GetAppsDomain(), Config.CfPushTimeoutDuration(), DEFAULT_MEMORY_LIMIT, "--no-route")
+		EnableDiego(appName, Config.DefaultTimeoutDuration())
+
+		port = CreateTcpRouteWithRandomPort(TestSetup.RegularUserContext().Space, domain, Config.DefaultTimeoutDuration())
+		address = net.JoinHostPort(domain, strconv.Itoa(int(port)))
+		Expect(cf.Cf("map-route", appName, domain, "--port", strconv.Itoa(int(port))).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+
+		StartApp(appName, Config.CfPushTimeoutDuration())
+	})
+
+	AfterEach(func() {
+		AppReport(appName, Config.DefaultTimeoutDuration())
+		DeleteApp(appName, Config.DefaultTimeoutDuration())
+
+		if restoreQuota != nil {
+			restoreQuota()
+			restoreQuota = nil
+		}
+	})
+
+	It("round-trips bytes through the TCP route", func() {
+		message := random_name.CATSRandomName("MESSAGE")
+
+		Eventually(func() (string, error) {
+			return sendLine(address, message)
+		}, Config.DefaultTimeoutDuration(), time.Second).Should(Equal("0:" + message))
+	})
+
+	It("balances connections across the instances of the app", func() {
+		ScaleAppInstances(appName, 2, Config.CfPushTimeoutDuration())
+
+		instances := map[string]bool{}
+		Eventually(func() map[string]bool {
+			reply, err := sendLine(address, "ping")
+			if err == nil {
+				instances[strings.SplitN(reply, ":", 2)[0]] = true
+			}
+			return instances
+		}, Config.DefaultTimeoutDuration(), 100*time.Millisecond).Should(And(HaveKey("0"), HaveKey("1")))
+	})
+
+	It("stops routing once the route is deleted", func() {
+		Eventually(func() error {
+			_, err := sendLine(address, "ping")
+			return err
+		}, Config.DefaultTimeoutDuration(), time.Second).ShouldNot(HaveOccurred())
+
+		Expect(cf.Cf("delete-route", domain, "--port", strconv.Itoa(int(port)), "-f").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+
+		Eventually(func() error {
+			_, err := sendLine(address, "ping")
+			return err
+		}, Config.DefaultTimeoutDuration(), time.Second).Should(HaveOccurred())
+	})
+})
@@ -0,0 +1,217 @@
+package user_provided_services
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"time"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/app_helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/assets"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/skip_messages"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+	. "github.com/onsi/gomega/gexec"
+)
+
+type userProvidedService struct {
+	Name           string                 `json:"name"`
+	Label          string                 `json:"label"`
+	Credentials    map[string]interface{} `json:"credentials"`
+	SyslogDrainUrl string                 `json:"syslog_drain_url"`
+}
+
+// userProvidedServices returns the user-provided entries of the app's
+// VCAP_SERVICES, as seen by the running app.
+func userProvidedServices(appName string) []userProvidedService {
+	var vcapServices map[string][]userProvidedService
+	Expect(json.Unmarshal([]byte(helpers.CurlApp(Config, appName, "/env/VCAP_SERVICES")), &vcapServices)).To(Succeed())
+	return vcapServices["user-provided"]
+}
+
+func pushApp(appName, asset, buildpack string, args ...string) {
+	push := append([]string{"push", appName,
+		"--no-start",
+		"-b", buildpack,
+		"-m", DEFAULT_MEMORY_LIMIT,
+		"-p", asset,
+		"-d", Config.GetAppsDomain(),
+	}, args...)
+	Expect(cf.Cf(push...).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+	app_helpers.SetBackend(appName)
+}
+
+func startApp(appName string) {
+	Expect(cf.Cf("start", appName).Wait(Config.CfPushTimeoutDuration())).To(Exit(0))
+}
+
+func deleteApp(appName string) {
+	app_helpers.AppReport(appName, Config.DefaultTimeoutDuration())
+	Expect(cf.Cf("delete", appName, "-f", "-r").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+}
+
+var _ = UserProvidedServicesDescribe("User-provided services", func() {
+	var (
+		instanceName string
+		appName      string
+	)
+
+	BeforeEach(func() {
+		instanceName = random_name.CATSRandomName("SVIN")
+		appName = random_name.CATSRandomName("APP")
+	})
+
+	AfterEach(func() {
+		Expect(cf.Cf("delete-service", instanceName, "-f").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+	})
+
+	Describe("with credentials", func() {
+		BeforeEach(func() {
+			Expect(cf.Cf("create-user-provided-service", instanceName,
+				"-p", `{"username":"cats-user","password":"first-password"}`,
+			).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+
+			pushApp(appName, assets.NewAssets().Dora, Config.GetRubyBuildpackName())
+			Expect(cf.Cf("bind-service", appName, instanceName).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			startApp(appName)
+		})
+
+		AfterEach(func() {
+			deleteApp(appName)
+		})
+
+		It("exposes the credentials to bound apps in VCAP_SERVICES", func() {
+			services := userProvidedServices(appName)
+			Expect(services).To(HaveLen(1))
+			Expect(services[0].Name).To(Equal(instanceName))
+			Expect(services[0].Label).To(Equal("user-provided"))
+			Expect(services[0].Credentials).To(Equal(map[string]interface{}{
+				"username": "cats-user",
+				"password": "first-password",
+			}))
+		})
+
+		It("exposes updated credentials once the app is restaged", func() {
+			Expect(cf.Cf("update-user-provided-service", instanceName,
+				"-p", `{"username":"cats-user","password":"second-password"}`,
+			).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+
+			Expect(userProvidedServices(appName)[0].Credentials).To(HaveKeyWithValue("password", "first-password"))
+
+			Expect(cf.Cf("restage", appName).Wait(Config.CfPushTimeoutDuration())).To(Exit(0))
+
+			Expect(userProvidedServices(appName)[0].Credentials).To(HaveKeyWithValue("password", "second-password"))
+		})
+	})
+
+	Describe("with a syslog drain url", func() {
+		var (
+			listenerAppName string
+			logs            *Session
+			interrupt       chan struct{}
+		)
+
+		BeforeEach(func() {
+			interrupt = make(chan struct{})
+			logs = nil
+			listenerAppName = random_name.CATSRandomName("APP")
+
+			pushApp(listenerAppName, assets.NewAssets().SyslogDrainListener, Config.GetGoBuildpackName(),
+				"--health-check-type", "port",
+				"-f", assets.NewAssets().SyslogDrainListener+"/manifest.yml",
+			)
+			startApp(listenerAppName)
+
+			pushApp(appName, assets.NewAssets().Dora, Config.GetRubyBuildpackName())
+			startApp(appName)
+		})
+
+		AfterEach(func() {
+			if logs != nil {
+				logs.Kill()
+			}
+			close(interrupt)
+
+			deleteApp(appName)
+			deleteApp(listenerAppName)
+		})
+
+		It("forwards the logs of bound apps to the drain", func() {
+			syslogDrainUrl := "syslog://" + app_helpers.GetSyslogDrainAddress(listenerAppName)
+			Expect(cf.Cf("create-user-provided-service", instanceName, "-l", syslogDrainUrl).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			Expect(cf.Cf("bind-service", appName, instanceName).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			Expect(cf.Cf("restage", appName).Wait(Config.CfPushTimeoutDuration())).To(Exit(0))
+
+			services := userProvidedServices(appName)
+			Expect(services).To(HaveLen(1))
+			Expect(services[0].SyslogDrainUrl).To(Equal(syslogDrainUrl))
+
+			logs = cf.Cf("logs", listenerAppName)
+			message := random_name.CATSRandomName("RANDOM-MESSAGE")
+			go app_helpers.WriteLogsUntilInterrupted(interrupt, appName, "/loglines/1/"+message)
+
+			Eventually(logs, Config.DefaultTimeoutDuration()+time.Minute).Should(Say("%s", message))
+		})
+	})
+
+	Describe("with a route service url", func() {
+		var (
+			routeServiceName string
+			bound            bool
+		)
+
+		BeforeEach(func() {
+			bound = false
+			if !Config.GetIncludeRouteServices() {
+				Skip(skip_messages.SkipRouteServicesMessage)
+			}
+			if Config.GetBackend() != "diego" {
+				Skip(skip_messages.SkipDiegoMessage)
+			}
+
+			routeServiceName = random_name.CATSRandomName("APP")
+			pushApp(routeServiceName, assets.NewAssets().GoRouteService, Config.GetGoBuildpackName())
+			Expect(cf.Cf("set-env", routeServiceName, "SKIP_SSL_VALIDATION", strconv.FormatBool(Config.GetSkipSSLValidation())).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			startApp(routeServiceName)
+
+			pushApp(appName, assets.NewAssets().Golang, Config.GetGoBuildpackName())
+			startApp(appName)
+
+			routeServiceUrl, err := url.Parse(helpers.AppUri(routeServiceName, "/", Config))
+			Expect(err).NotTo(HaveOccurred())
+			routeServiceUrl.Scheme = "https"
+
+			Expect(cf.Cf("create-user-provided-service", instanceName, "-r", routeServiceUrl.String()).Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			Expect(cf.Cf("bind-route-service", Config.GetAppsDomain(), instanceName, "--hostname", appName, "-f").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			bound = true
+		})
+
+		AfterEach(func() {
+			if bound {
+				Expect(cf.Cf("unbind-route-service", Config.GetAppsDomain(), instanceName, "--hostname", appName, "-f").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+			}
+
+			deleteApp(appName)
+			deleteApp(routeServiceName)
+		})
+
+		It("routes requests to the app through the route service", func() {
+			appUrl, err := url.Parse(helpers.AppUri(appName, "/", Config))
+			Expect(err).NotTo(HaveOccurred())
+
+			// assets/go-route-service answers "echo" requests itself, so the
+			// response only contains the forwarded url when it is in the path.
+			Eventually(func() string {
+				return helpers.CurlApp(Config, appName, "/", "-H", "X-Cats-Route-Service-Mode: echo")
+			}, Config.DefaultTimeoutDuration()).Should(ContainSubstring(appUrl.Host))
+
+			Expect(helpers.CurlAppRoot(Config, appName)).To(ContainSubstring("go, world"))
+		})
+	})
+})
@@ -0,0 +1,171 @@
+package v3
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+
+	archive_helpers "code.cloudfoundry.org/archiver/extractor/test_helper"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/v3_helpers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+)
+
+var _ = V3Describe("multiple process types", func() {
+	var (
+		appName     string
+		appGuid     string
+		packageGuid string
+		spaceGuid   string
+		token       string
+		appZip      string
+	)
+
+	BeforeEach(func() {
+		appName = random_name.CATSRandomName("APP")
+		spaceGuid = GetSpaceGuidFromName(TestSetup.RegularUserContext().Space)
+		appGuid = CreateApp(appName, spaceGuid, "{}")
+		packageGuid = CreatePackage(appGuid)
+		token = GetAuthToken()
+
+		appZip = createMultiProcessApp()
+		uploadUrl := fmt.Sprintf("%s%s/v3/packages/%s/upload", Config.Protocol(), Config.GetApiEndpoint(), packageGuid)
+		UploadPackage(uploadUrl, appZip, token)
+		WaitForPackageToBeReady(packageGuid)
+
+		dropletGuid := StageBuildpackPackage(packageGuid, Config.GetBinaryBuildpackName())
+		WaitForDropletToStage(dropletGuid)
+		AssignDropletToApp(appGuid, dropletGuid)
+
+		processes := GetProcesses(appGuid, appName)
+		for _, processType := range []string{"worker", "clock"} {
+			process := GetProcessByType(processes, processType)
+			Expect(process.Guid).ToNot(BeEmpty(), "missing process type "+processType)
+			UpdateProcessHealthCheck(process.Guid, "process")
+		}
+	})
+
+	AfterEach(func() {
+		FetchRecentLogs(appGuid, token, Config)
+		DeleteApp(appGuid)
+		os.RemoveAll(path.Dir(appZip))
+	})
+
+	It("scales each process type independently", func() {
+		ScaleProcessInstances(appGuid, "web", 1)
+		ScaleProcess(appGuid, "web", V3_DEFAULT_MEMORY_LIMIT)
+
+		ScaleProcessInstances(appGuid, "worker", 2)
+		ScaleProcess(appGuid, "worker", "128")
+		ScaleProcessDisk(appGuid, "worker", "512")
+
+		ScaleProcessInstances(appGuid, "clock", 3)
+		ScaleProcess(appGuid, "clock", "64")
+
+		processes := GetProcesses(appGuid, appName)
+		web := GetProcessByType(processes, "web")
+		worker := GetProcessByType(processes, "worker")
+		clock := GetProcessByType(processes, "clock")
+
+		Expect(web.Instances).To(Equal(1))
+		Expect(web.MemoryInMB).To(Equal(256))
+		Expect(web.HealthCheck.Type).To(Equal("port"))
+
+		Expect(worker.Instances).To(Equal(2))
+		Expect(worker.MemoryInMB).To(Equal(128))
+		Expect(worker.DiskInMB).To(Equal(512))
+		Expect(worker.HealthCheck.Type).To(Equal("process"))
+
+		Expect(clock.Instances).To(Equal(3))
+		Expect(clock.MemoryInMB).To(Equal(64))
+		Expect(clock.DiskInMB).To(Equal(web.DiskInMB))
+		Expect(clock.HealthCheck.Type).To(Equal("process"))
+
+		StartApp(appGuid)
+
+		for processType, instances := range map[string]int{"web": 1, "worker": 2, "clock": 3} {
+			Eventually(func() []string {
+				return processInstanceStates(appGuid, processType)
+			}, Config.CfPushTimeoutDuration()).Should(Equal(repeat("RUNNING", instances)), processType+" did not reach the expected instance count")
+		}
+	})
+
+	It("restarts only the process type whose instance was killed", func() {
+		StartApp(appGuid)
+
+		for _, processType := range []string{"web", "worker", "clock"} {
+			Eventually(func() []string {
+				return processInstanceStates(appGuid, processType)
+			}, Config.CfPushTimeoutDuration()).Should(Equal([]string{"RUNNING"}), processType+" did not start")
+		}
+
+		web := GetProcessInstances(appGuid, "web")
+		clock := GetProcessInstances(appGuid, "clock")
+		worker := GetProcessInstances(appGuid, "worker")
+		Expect(web).To(HaveLen(1))
+		Expect(clock).To(HaveLen(1))
+		Expect(worker).To(HaveLen(1))
+		webUptime, clockUptime, workerUptime := web[0].Uptime, clock[0].Uptime, worker[0].Uptime
+
+		By("terminating the worker instance")
+		terminateUrl := fmt.Sprintf("/v3/apps/%s/processes/worker/instances/0", appGuid)
+		Expect(cf.Cf("curl", terminateUrl, "-X", "DELETE").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+
+		By("waiting for the worker to be restarted")
+		Eventually(func() bool {
+			instances := GetProcessInstances(appGuid, "worker")
+			return len(instances) == 1 && instances[0].State == "RUNNING" && instances[0].Uptime < workerUptime
+		}, Config.CfPushTimeoutDuration()).Should(BeTrue())
+
+		By("ensuring the other process types kept running")
+		web = GetProcessInstances(appGuid, "web")
+		clock = GetProcessInstances(appGuid, "clock")
+		Expect(web).To(HaveLen(1))
+		Expect(clock).To(HaveLen(1))
+		Expect(web[0].State).To(Equal("RUNNING"))
+		Expect(web[0].Uptime).To(BeNumerically(">=", webUptime))
+		Expect(clock[0].State).To(Equal("RUNNING"))
+		Expect(clock[0].Uptime).To(BeNumerically(">=", clockUptime))
+	})
+})
+
+func processInstanceStates(appGuid, processType string) []string {
+	states := []string{}
+	for _, instance := range GetProcessInstances(appGuid, processType) {
+		states = append(states, instance.State)
+	}
+	return states
+}
+
+func repeat(value string, count int) []string {
+	values := make([]string, count)
+	for i := range values {
+		values[i] = value
+	}
+	return values
+}
+
+func createMultiProcessApp() string {
+	tmpPath, err := ioutil.TempDir("", "multi-process-cats")
+	Expect(err).ToNot(HaveOccurred())
+
+	appArchivePath := path.Join(tmpPath, "app.zip")
+
+	archive_helpers.CreateZipArchive(appArchivePath, []archive_helpers.ArchiveFile{
+		{
+			Name: "Procfile",
+			Body: `web: while true; do { echo -e 'HTTP/1.1 200 OK\r\n'; echo "web"; } | nc -l $PORT; done
+worker: while true; do echo "worker $CF_INSTANCE_INDEX"; sleep 5; done
+clock: while true; do echo "clock $CF_INSTANCE_INDEX"; sleep 5; done
+`,
+		},
+	})
+
+	return appArchivePath
+}
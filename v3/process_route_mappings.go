@@ -0,0 +1,264 @@
+package v3
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	. "github.com/cloudfoundry/cf-acceptance-tests/cats_suite_helpers"
+
+	archive_helpers "code.cloudfoundry.org/archiver/extractor/test_helper"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/cf"
+	"github.com/cloudfoundry-incubator/cf-test-helpers/helpers"
+	"github.com/cloudfoundry/cf-acceptance-tests/helpers/random_name"
+	. "github.com/cloudfoundry/cf-acceptance-tests/helpers/v3_helpers"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gexec"
+)
+
+const (
+	portsMappedError         = "ports may not be removed while routes are mapped to them"
+	routePortNotEnabledError = "Routes can only be mapped to ports already enabled for the application."
+)
+
+type v3Error struct {
+	Code   int    `json:"code"`
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+}
+
+type v2Error struct {
+	Code        int    `json:"code"`
+	Description string `json:"description"`
+	ErrorCode   string `json:"error_code"`
+}
+
+// v3Curl sends a request to the v3 API and returns the guid of the resource
+// it responds with, and the errors it failed with.
+func v3Curl(path, method, body string) (string, []v3Error) {
+	session := cf.Cf("curl", path, "-X", method, "-d", body).Wait(Config.DefaultTimeoutDuration())
+	Expect(session).To(Exit(0))
+
+	var response struct {
+		Guid   string    `json:"guid"`
+		Errors []v3Error `json:"errors"`
+	}
+	Expect(json.Unmarshal(session.Out.Contents(), &response)).To(Succeed())
+	return response.Guid, response.Errors
+}
+
+// v2Curl sends a request to the v2 API and returns the guid of the resource
+// it responds with, and the error it failed with, which is empty on success.
+func v2Curl(path, method, body string) (string, v2Error) {
+	session := cf.Cf("curl", path, "-X", method, "-d", body).Wait(Config.DefaultTimeoutDuration())
+	Expect(session).To(Exit(0))
+
+	var response struct {
+		Metadata struct {
+			Guid string `json:"guid"`
+		} `json:"metadata"`
+		v2Error
+	}
+	Expect(json.Unmarshal(session.Out.Contents(), &response)).To(Succeed())
+	return response.Metadata.Guid, response.v2Error
+}
+
+func routeGuid(host string) string {
+	routes := cf.Cf("curl", fmt.Sprintf("/v2/routes?q=host:%s", host)).Wait(Config.DefaultTimeoutDuration())
+	Expect(routes).To(Exit(0))
+
+	var routeJSON struct {
+		Resources []struct {
+			Metadata struct {
+				Guid string `json:"guid"`
+			} `json:"metadata"`
+		} `json:"resources"`
+	}
+	Expect(json.Unmarshal(routes.Out.Contents(), &routeJSON)).To(Succeed())
+	Expect(routeJSON.Resources).To(HaveLen(1))
+	return routeJSON.Resources[0].Metadata.Guid
+}
+
+// processPort is a port of a process type that a route can be mapped to.
+type processPort struct {
+	Type string
+	Port int
+}
+
+// String is the response of the process on the port.
+func (p processPort) String() string {
+	return fmt.Sprintf("%s %d", p.Type, p.Port)
+}
+
+var (
+	web8080    = processPort{"web", 8080}
+	web9090    = processPort{"web", 9090}
+	worker8080 = processPort{"worker", 8080}
+)
+
+func setProcessPorts(processGuid string, ports ...int) []v3Error {
+	encoded, err := json.Marshal(ports)
+	Expect(err).NotTo(HaveOccurred())
+
+	_, errors := v3Curl(fmt.Sprintf("/v3/processes/%s", processGuid), "PATCH", fmt.Sprintf(`{"ports":%s}`, encoded))
+	return errors
+}
+
+// mapRouteToProcessType maps the route to the default port of a process type.
+// The v3 route mappings endpoint ignores app_port and always maps to 8080.
+func mapRouteToProcessType(appGuid, host, processType string) []v3Error {
+	_, errors := v3Curl("/v3/route_mappings", "POST", fmt.Sprintf(`
+		{
+			"relationships": {
+				"app":     {"guid": "%s"},
+				"route":   {"guid": "%s"},
+				"process": {"type": "%s"}
+			}
+		}`, appGuid, routeGuid(host), processType))
+	return errors
+}
+
+// mapRouteToWebPort maps the route to a port of the web process through the
+// v2 route mappings endpoint, which honours app_port. The guid of a v3 app is
+// the guid of its web process in the v2 API.
+func mapRouteToWebPort(appGuid, host string, port int) (string, v2Error) {
+	return v2Curl("/v2/route_mappings", "POST", fmt.Sprintf(`
+		{
+			"app_guid":   "%s",
+			"route_guid": "%s",
+			"app_port":   %d
+		}`, appGuid, routeGuid(host), port))
+}
+
+// mapRoute maps the route to the process type and port of target.
+func mapRoute(appGuid, host string, target processPort) {
+	if target.Port == web8080.Port {
+		Expect(mapRouteToProcessType(appGuid, host, target.Type)).To(BeEmpty())
+		return
+	}
+
+	Expect(target.Type).To(Equal("web"), "only the web process can be mapped to a port other than 8080")
+	_, failure := mapRouteToWebPort(appGuid, host, target.Port)
+	Expect(failure).To(BeZero())
+}
+
+var _ = V3Describe("route mappings to process types and ports", func() {
+	var (
+		appName       string
+		appGuid       string
+		token         string
+		appZip        string
+		webProcess    Process
+		workerProcess Process
+		hosts         map[processPort]string
+	)
+
+	BeforeEach(func() {
+		appName = random_name.CATSRandomName("APP")
+		spaceName := TestSetup.RegularUserContext().Space
+		appGuid = CreateApp(appName, GetSpaceGuidFromName(spaceName), "{}")
+		packageGuid := CreatePackage(appGuid)
+		token = GetAuthToken()
+
+		appZip = createMultiPortApp()
+		uploadUrl := fmt.Sprintf("%s%s/v3/packages/%s/upload", Config.Protocol(), Config.GetApiEndpoint(), packageGuid)
+		UploadPackage(uploadUrl, appZip, token)
+		WaitForPackageToBeReady(packageGuid)
+
+		dropletGuid := StageBuildpackPackage(packageGuid, Config.GetBinaryBuildpackName())
+		WaitForDropletToStage(dropletGuid)
+		AssignDropletToApp(appGuid, dropletGuid)
+
+		processes := GetProcesses(appGuid, appName)
+		webProcess = GetProcessByType(processes, "web")
+		workerProcess = GetProcessByType(processes, "worker")
+		Expect(workerProcess.Guid).NotTo(BeEmpty(), "missing process type worker")
+		UpdateProcessHealthCheck(workerProcess.Guid, "process")
+		ScaleProcessInstances(appGuid, "worker", 1)
+
+		Expect(setProcessPorts(webProcess.Guid, 8080, 9090)).To(BeEmpty())
+		Expect(setProcessPorts(workerProcess.Guid, 8080)).To(BeEmpty())
+
+		hosts = map[processPort]string{}
+		for _, target := range []processPort{web8080, web9090, worker8080} {
+			host := strings.ToLower(random_name.CATSRandomName("ROUTE"))
+			CreateRoute(spaceName, Config.GetAppsDomain(), host)
+			hosts[target] = host
+		}
+	})
+
+	AfterEach(func() {
+		FetchRecentLogs(appGuid, token, Config)
+		DeleteApp(appGuid)
+		for _, host := range hosts {
+			Expect(cf.Cf("delete-route", Config.GetAppsDomain(), "-n", host, "-f").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		}
+		os.RemoveAll(path.Dir(appZip))
+	})
+
+	It("routes each route to the process type and port it is mapped to", func() {
+		for target, host := range hosts {
+			mapRoute(appGuid, host, target)
+		}
+
+		StartApp(appGuid)
+
+		for target, host := range hosts {
+			Eventually(func() string {
+				return helpers.CurlAppRoot(Config, host)
+			}, Config.CfPushTimeoutDuration()).Should(ContainSubstring(target.String()))
+		}
+	})
+
+	It("rejects a mapping to a port the process does not expose", func() {
+		_, failure := mapRouteToWebPort(appGuid, hosts[web9090], 7070)
+
+		Expect(failure.ErrorCode).To(Equal("CF-RoutePortNotEnabledOnApp"))
+		Expect(failure.Description).To(Equal(routePortNotEnabledError))
+	})
+
+	It("keeps a port while a route is mapped to it", func() {
+		mappingGuid, failure := mapRouteToWebPort(appGuid, hosts[web9090], web9090.Port)
+		Expect(failure).To(BeZero())
+
+		StartApp(appGuid)
+		Eventually(func() string {
+			return helpers.CurlAppRoot(Config, hosts[web9090])
+		}, Config.CfPushTimeoutDuration()).Should(ContainSubstring(web9090.String()))
+
+		errors := setProcessPorts(webProcess.Guid, 8080)
+		Expect(errors).To(HaveLen(1))
+		Expect(errors[0].Title).To(Equal("CF-UnprocessableEntity"))
+		Expect(errors[0].Detail).To(ContainSubstring(portsMappedError))
+
+		Expect(cf.Cf("curl", fmt.Sprintf("/v2/route_mappings/%s", mappingGuid), "-X", "DELETE").Wait(Config.DefaultTimeoutDuration())).To(Exit(0))
+		Expect(setProcessPorts(webProcess.Guid, 8080)).To(BeEmpty())
+
+		Eventually(func() string {
+			return helpers.CurlAppRoot(Config, hosts[web9090])
+		}, Config.DefaultTimeoutDuration()).Should(ContainSubstring("404 Not Found"))
+	})
+})
+
+// createMultiPortApp creates an app whose web and worker processes both
+// answer on ports 8080 and 9090 with their type and the port.
+func createMultiPortApp() string {
+	tmpPath, err := ioutil.TempDir("", "multi-port-cats")
+	Expect(err).ToNot(HaveOccurred())
+
+	appArchivePath := path.Join(tmpPath, "app.zip")
+
+	serve := `for port in 8080 9090; do (while true; do { echo -e 'HTTP/1.1 200 OK\r\n'; echo "%s $port"; } | nc -l $port; done) & done; wait`
+	archive_helpers.CreateZipArchive(appArchivePath, []archive_helpers.ArchiveFile{
+		{
+			Name: "Procfile",
+			Body: fmt.Sprintf("web: "+serve+"\nworker: "+serve+"\n", "web", "worker"),
+		},
+	})
+
+	return appArchivePath
+}